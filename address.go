@@ -2,57 +2,97 @@ package underflow
 
 import "github.com/onflow/cadence"
 
+// ExtractedAddress is an address found inside a cadence value together with where it was found
+type ExtractedAddress struct {
+	// the address as a 0x prefixed hex string
+	Address string
+	// the path to the address from the root value, like .offers[2].buyer
+	//  dictionary values are addressed with [key] and dictionary keys with {key}
+	Path string
+	// the type id of the closest enclosing struct, resource, event, enum, contract or attachment, empty if there is none
+	Type string
+	// the name of the address in the address book, empty if it is not in the book
+	Name string
+}
+
+// ExtractAddressOptions controls how ExtractAddressesWithOption extracts addresses
+type ExtractAddressOptions struct {
+	// only return the first occurrence of every address
+	Dedupe bool
+	// look up the name of every address in this address book
+	AddressBook AddressBook
+}
+
 // / This functions extracts out addresses from a cadence value
-// /  It currently supports arrays, optionls, dictionaries and structs
+// /  It supports every kind of cadence value, including dictionary keys, events, resources and capabilities
 func ExtractAddresses(field cadence.Value) []string {
-	if field == nil {
+	extracted := ExtractAddressesWithOption(field, ExtractAddressOptions{})
+	if len(extracted) == 0 {
 		return nil
 	}
 
-	switch field := field.(type) {
-	case cadence.Optional:
-		return ExtractAddresses(field.Value)
-	case cadence.Dictionary:
-		result := []string{}
-		for _, item := range field.Pairs {
-			value := ExtractAddresses(item.Value)
-			key := getAndUnquoteString(item.Key)
+	result := make([]string, len(extracted))
+	for i, address := range extracted {
+		result[i] = address.Address
+	}
+	return result
+}
 
-			if value != nil && key != "" {
-				result = append(result, value...)
-			}
-		}
-		if len(result) == 0 {
-			return nil
-		}
-		return result
-	case cadence.Struct:
-		result := []string{}
-		for _, subField := range field.Fields {
-			value := ExtractAddresses(subField)
-			if value != nil {
-				result = append(result, value...)
-			}
-		}
-		if len(result) == 0 {
-			return nil
-		}
+// / Extract out all addresses from a cadence value with the path and enclosing type of each address
+func ExtractAddressesWithOption(field cadence.Value, opt ExtractAddressOptions) []ExtractedAddress {
+	extractor := &addressExtractor{book: opt.AddressBook, result: []ExtractedAddress{}}
+	// the extractor never returns an error
+	_ = Walk(field, extractor)
+	result := extractor.result
+
+	if !opt.Dedupe {
 		return result
-	case cadence.Array:
-		result := []string{}
-		for _, item := range field.Values {
-			value := ExtractAddresses(item)
-			if value != nil {
-				result = append(result, value...)
-			}
-		}
-		if len(result) == 0 {
-			return nil
+	}
+
+	seen := map[string]bool{}
+	deduped := []ExtractedAddress{}
+	for _, address := range result {
+		if seen[address.Address] {
+			continue
 		}
-		return result
-	case cadence.Address:
-		return []string{field.String()}
-	default:
-		return []string{}
+		seen[address.Address] = true
+		deduped = append(deduped, address)
+	}
+	return deduped
+}
+
+// addressExtractor is a Visitor that collects addresses and keeps track of the enclosing composite types
+type addressExtractor struct {
+	book   AddressBook
+	types  []string
+	result []ExtractedAddress
+}
+
+func (e *addressExtractor) Enter(path ValuePath, value cadence.Value) error {
+	if _, _, ok := composite(value); ok {
+		// composites without type are pushed as well, their addresses have no enclosing type
+		typeID, _ := compositeTypeID(value)
+		e.types = append(e.types, typeID)
+		return nil
+	}
+
+	address, ok := value.(cadence.Address)
+	if !ok {
+		return nil
+	}
+
+	typeID := ""
+	if len(e.types) > 0 {
+		typeID = e.types[len(e.types)-1]
+	}
+	name, _ := e.book.Name(address)
+	e.result = append(e.result, ExtractedAddress{Address: address.String(), Path: path.String(), Type: typeID, Name: name})
+	return nil
+}
+
+func (e *addressExtractor) Leave(path ValuePath, value cadence.Value) error {
+	if _, _, ok := composite(value); ok {
+		e.types = e.types[:len(e.types)-1]
 	}
+	return nil
 }
@@ -0,0 +1,46 @@
+package underflow
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence"
+)
+
+// AddressBook maps addresses as 0x prefixed hex strings to human readable names like emulator-first
+type AddressBook map[string]string
+
+// / Create an address book from the accounts section of a flow.json file
+func NewAddressBookFromFlowJson(path string) (AddressBook, error) {
+	flowJson, err := ReadFlowJson(path)
+	if err != nil {
+		return nil, err
+	}
+	return flowJson.AddressBook(), nil
+}
+
+// / Create an address book with the names of all the accounts in flow.json
+func (f *FlowJson) AddressBook() AddressBook {
+	book := AddressBook{}
+	for name, account := range f.Accounts {
+		book["0x"+normalizeFlowJsonAddress(account.Address)] = name
+	}
+	return book
+}
+
+// / Look up the name of an address
+func (b AddressBook) Name(address cadence.Address) (string, bool) {
+	name, ok := b[address.String()]
+	return name, ok
+}
+
+// / Format an address as its name, or as name (0x...) if includeHex is set. Addresses that are not in the book are formatted as hex
+func (b AddressBook) Format(address cadence.Address, includeHex bool) string {
+	name, ok := b.Name(address)
+	if !ok {
+		return address.String()
+	}
+	if includeHex {
+		return fmt.Sprintf("%s (%s)", name, address.String())
+	}
+	return name
+}
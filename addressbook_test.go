@@ -0,0 +1,43 @@
+package underflow
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddressBook(t *testing.T) {
+	book, err := NewAddressBookFromFlowJson("flow.json")
+	require.NoError(t, err)
+
+	first, err := hexToAddress("01cf0e2f2f715450")
+	require.NoError(t, err)
+	unknown, err := hexToAddress("0000000000000042")
+	require.NoError(t, err)
+
+	dict := cadence.NewDictionary([]cadence.KeyValuePair{
+		{Key: *first, Value: cadence.NewArray([]cadence.Value{*first, *unknown})},
+	})
+
+	t.Run("names", func(t *testing.T) {
+		value := CadenceValueToInterfaceWithOption(dict, Options{AddressBook: book})
+		assert.Equal(t, map[string]interface{}{
+			"emulator-first": []interface{}{"emulator-first", "0x0000000000000042"},
+		}, value)
+	})
+
+	t.Run("names with hex", func(t *testing.T) {
+		value := CadenceValueToInterfaceWithOption(*first, Options{AddressBook: book, AddressBookIncludeHex: true})
+		assert.Equal(t, "emulator-first (0x01cf0e2f2f715450)", value)
+	})
+
+	t.Run("extract addresses", func(t *testing.T) {
+		result := ExtractAddressesWithOption(dict, ExtractAddressOptions{AddressBook: book, Dedupe: true})
+		assert.Equal(t, []ExtractedAddress{
+			{Address: "0x01cf0e2f2f715450", Path: "{0x01cf0e2f2f715450}", Name: "emulator-first"},
+			{Address: "0x0000000000000042", Path: "[0x01cf0e2f2f715450][1]"},
+		}, result)
+	})
+}
@@ -0,0 +1,325 @@
+package underflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/parser"
+)
+
+// Parameter is a parameter of a script or transaction, the type is written like in cadence, for instance {String: UFix64}
+type Parameter struct {
+	Name string
+	Type string
+}
+
+// / Read the parameters of the main function of a script or of a transaction from its code
+func ScriptParameters(code string) ([]Parameter, error) {
+	program, err := parser.ParseProgram(nil, []byte(code), parser.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	var parameterList *ast.ParameterList
+	for _, function := range program.FunctionDeclarations() {
+		if function.Identifier.Identifier == "main" {
+			parameterList = function.ParameterList
+		}
+	}
+	if transaction := program.SoleTransactionDeclaration(); transaction != nil {
+		parameterList = transaction.ParameterList
+	}
+	if parameterList == nil {
+		return nil, fmt.Errorf("the code has no main function and is not a transaction")
+	}
+
+	parameters := []Parameter{}
+	for _, parameter := range parameterList.Parameters {
+		parameters = append(parameters, Parameter{Name: parameter.Identifier.Identifier, Type: parameter.TypeAnnotation.Type.String()})
+	}
+	return parameters, nil
+}
+
+// ArgumentError is an argument that could not be converted, the field is the path to the value like ids[1]
+type ArgumentError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ArgumentsError has all the arguments that could not be converted
+type ArgumentsError struct {
+	Errors []ArgumentError `json:"errors"`
+}
+
+func (e *ArgumentsError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		if err.Field == "" {
+			messages[i] = err.Message
+		} else {
+			messages[i] = err.Field + ": " + err.Message
+		}
+	}
+	return "invalid arguments: " + strings.Join(messages, ", ")
+}
+
+func (e *ArgumentsError) add(field string, format string, args ...interface{}) {
+	e.Errors = append(e.Errors, ArgumentError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// ArgumentDecoder converts json into the arguments of a script, the types are converted like cadence:"name,type=..." tags
+type ArgumentDecoder struct {
+	parameters []Parameter
+	types      []cadence.Type
+	opt        Options
+}
+
+// / Create a decoder for the parameters, the types are parsed once so an unsupported type is an error here
+func NewArgumentDecoder(parameters []Parameter, opt Options) (*ArgumentDecoder, error) {
+	types := make([]cadence.Type, len(parameters))
+	for i, parameter := range parameters {
+		typ, err := ParseCadenceType(parameter.Type)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", parameter.Name, err)
+		}
+		types[i] = typ
+	}
+	return &ArgumentDecoder{parameters: parameters, types: types, opt: opt}, nil
+}
+
+// / Decode a json object with the parameter names as keys or a json array with the arguments in order
+// /  An empty body is an empty object, errors are *ArgumentsError naming every bad field
+func (d *ArgumentDecoder) Decode(data []byte) ([]cadence.Value, error) {
+	result := &ArgumentsError{}
+	if len(bytes.TrimSpace(data)) == 0 {
+		data = []byte("{}")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var body interface{}
+	if err := decoder.Decode(&body); err != nil {
+		result.add("", "the body is not valid json: %v", err)
+		return nil, result
+	}
+	var extra interface{}
+	if err := decoder.Decode(&extra); !errors.Is(err, io.EOF) {
+		result.add("", "the body must be a single json value, there is more after it")
+		return nil, result
+	}
+
+	var values []interface{}
+	switch body := body.(type) {
+	case map[string]interface{}:
+		values = make([]interface{}, len(d.parameters))
+		known := map[string]bool{}
+		for i, parameter := range d.parameters {
+			known[parameter.Name] = true
+			value, ok := body[parameter.Name]
+			if !ok {
+				result.add(parameter.Name, "it is required")
+				continue
+			}
+			values[i] = value
+		}
+		for _, name := range sortedMapKeys(body) {
+			if !known[name] {
+				result.add(name, "unknown argument")
+			}
+		}
+	case []interface{}:
+		if len(body) != len(d.parameters) {
+			result.add("", "expected %d arguments, got %d", len(d.parameters), len(body))
+			return nil, result
+		}
+		values = body
+	default:
+		result.add("", "the body must be a json object or array")
+		return nil, result
+	}
+	if len(result.Errors) > 0 {
+		return nil, result
+	}
+
+	arguments := make([]cadence.Value, len(d.parameters))
+	for i, parameter := range d.parameters {
+		arguments[i] = jsonToCadenceType(values[i], d.types[i], parameter.Name, d.opt, result)
+	}
+	if len(result.Errors) > 0 {
+		return nil, result
+	}
+	return arguments, nil
+}
+
+// convert a value decoded with UseNumber, errors are added to result with the path to the value
+func jsonToCadenceType(value interface{}, target cadence.Type, field string, opt Options, result *ArgumentsError) cadence.Value {
+	if optional, ok := target.(*cadence.OptionalType); ok {
+		if value == nil {
+			return cadence.NewOptional(nil)
+		}
+		inner := jsonToCadenceType(value, optional.Type, field, opt, result)
+		if inner == nil {
+			return nil
+		}
+		return cadence.NewOptional(inner)
+	}
+
+	if value == nil {
+		result.add(field, "expected %s, got null", target.ID())
+		return nil
+	}
+
+	switch target := target.(type) {
+	case *cadence.VariableSizedArrayType, *cadence.ConstantSizedArrayType:
+		array, ok := value.([]interface{})
+		if !ok {
+			result.add(field, "expected %s, got %s", target.ID(), describeJSON(value))
+			return nil
+		}
+		arrayType := target.(cadence.ArrayType)
+		if constant, ok := target.(*cadence.ConstantSizedArrayType); ok && uint(len(array)) != constant.Size {
+			result.add(field, "expected %d elements, got %d", constant.Size, len(array))
+			return nil
+		}
+		values := make([]cadence.Value, len(array))
+		for i, element := range array {
+			values[i] = jsonToCadenceType(element, arrayType.Element(), fmt.Sprintf("%s[%d]", field, i), opt, result)
+		}
+		return cadence.NewArray(values).WithType(arrayType)
+
+	case *cadence.DictionaryType:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			result.add(field, "expected %s, got %s", target.ID(), describeJSON(value))
+			return nil
+		}
+		pairs := make([]cadence.KeyValuePair, 0, len(object))
+		for _, name := range sortedMapKeys(object) {
+			// json keys are always strings, they are converted like string values
+			key, err := convertToCadenceType(reflect.ValueOf(name), target.KeyType, opt.Resolver, opt)
+			if err != nil {
+				result.add(field+"."+name, "%v", err)
+				continue
+			}
+			pairs = append(pairs, cadence.KeyValuePair{
+				Key:   key,
+				Value: jsonToCadenceType(object[name], target.ElementType, field+"."+name, opt, result),
+			})
+		}
+		sortKeyValuePairs(pairs, opt.keyLess())
+		return cadence.NewDictionary(pairs).WithType(target)
+	}
+
+	// numbers are kept as json.Number, which is a string, so they must not become strings or paths
+	if _, ok := value.(json.Number); ok {
+		if _, anyStruct := target.(cadence.AnyStructType); !anyStruct && !isNumberType(target) {
+			result.add(field, "expected %s, got a number", target.ID())
+			return nil
+		}
+	}
+	switch value.(type) {
+	case []interface{}, map[string]interface{}:
+		if _, anyStruct := target.(cadence.AnyStructType); !anyStruct {
+			result.add(field, "expected %s, got %s", target.ID(), describeJSON(value))
+			return nil
+		}
+	}
+
+	converted, err := convertToCadenceType(reflect.ValueOf(value), target, opt.Resolver, opt)
+	if err != nil {
+		result.add(field, "%v", err)
+		return nil
+	}
+	return converted
+}
+
+func describeJSON(value interface{}) string {
+	switch value.(type) {
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	case json.Number:
+		return "a number"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func sortedMapKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// the largest request body that is read
+const maxArgumentsBody = 1 << 20
+
+func (d *ArgumentDecoder) decodeRequest(w http.ResponseWriter, r *http.Request) ([]cadence.Value, bool) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxArgumentsBody))
+	if err != nil {
+		writeArgumentsError(w, &ArgumentsError{Errors: []ArgumentError{{Message: fmt.Sprintf("cannot read the body: %v", err)}}})
+		return nil, false
+	}
+	arguments, err := d.Decode(data)
+	if err != nil {
+		var argumentsErr *ArgumentsError
+		if !errors.As(err, &argumentsErr) {
+			argumentsErr = &ArgumentsError{Errors: []ArgumentError{{Message: err.Error()}}}
+		}
+		writeArgumentsError(w, argumentsErr)
+		return nil, false
+	}
+	return arguments, true
+}
+
+// the response is {"error": "invalid arguments", "errors": [{"field": "ids[1]", "message": "..."}]}
+func writeArgumentsError(w http.ResponseWriter, err *ArgumentsError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(struct {
+		Error  string          `json:"error"`
+		Errors []ArgumentError `json:"errors"`
+	}{Error: "invalid arguments", Errors: err.Errors})
+}
+
+// / A handler that decodes the arguments from the request body and calls next with them, invalid arguments get a 400 response
+func (d *ArgumentDecoder) Handler(next func(w http.ResponseWriter, r *http.Request, arguments []cadence.Value)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arguments, ok := d.decodeRequest(w, r)
+		if ok {
+			next(w, r, arguments)
+		}
+	})
+}
+
+type argumentsKey struct{}
+
+// / Middleware that decodes the arguments from the request body, next gets them with ArgumentsFromRequest
+func (d *ArgumentDecoder) Middleware(next http.Handler) http.Handler {
+	return d.Handler(func(w http.ResponseWriter, r *http.Request, arguments []cadence.Value) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), argumentsKey{}, arguments)))
+	})
+}
+
+// / The arguments decoded by ArgumentDecoder.Middleware, nil if the request did not go through it
+func ArgumentsFromRequest(r *http.Request) []cadence.Value {
+	arguments, _ := r.Context().Value(argumentsKey{}).([]cadence.Value)
+	return arguments
+}
@@ -0,0 +1,166 @@
+package underflow
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const listingsScript = `import Market from "./Market.cdc"
+
+pub fun main(ids: [UInt64], owner: Address, royalties: {String: UFix64}, limit: UInt8?, tag: String): [String] {
+	return []
+}`
+
+func testArgumentDecoder(t *testing.T) *ArgumentDecoder {
+	parameters, err := ScriptParameters(listingsScript)
+	require.NoError(t, err)
+	decoder, err := NewArgumentDecoder(parameters, defaultOptions)
+	require.NoError(t, err)
+	return decoder
+}
+
+func TestScriptParameters(t *testing.T) {
+	parameters, err := ScriptParameters(listingsScript)
+	require.NoError(t, err)
+	assert.Equal(t, []Parameter{
+		{Name: "ids", Type: "[UInt64]"},
+		{Name: "owner", Type: "Address"},
+		{Name: "royalties", Type: "{String: UFix64}"},
+		{Name: "limit", Type: "UInt8?"},
+		{Name: "tag", Type: "String"},
+	}, parameters)
+
+	parameters, err = ScriptParameters(`transaction(amount: UFix64) { prepare(account: AuthAccount) {} }`)
+	require.NoError(t, err)
+	assert.Equal(t, []Parameter{{Name: "amount", Type: "UFix64"}}, parameters)
+
+	_, err = ScriptParameters(`pub fun other() {}`)
+	assert.ErrorContains(t, err, "no main function")
+}
+
+func TestDecodeArguments(t *testing.T) {
+	decoder := testArgumentDecoder(t)
+	want := `[1, 2] 0xf8d6e0586b0a20c7 {"a": 0.05000000, "b": 1.00000000} nil "foo"`
+
+	tests := map[string]string{
+		"named":      `{"ids": [1, 2], "owner": "0xf8d6e0586b0a20c7", "royalties": {"b": 1, "a": "0.05"}, "limit": null, "tag": "foo"}`,
+		"positional": `[[1, 2], "0xf8d6e0586b0a20c7", {"b": 1, "a": 0.05}, null, "foo"]`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			arguments, err := decoder.Decode([]byte(body))
+			require.NoError(t, err)
+			values := []string{}
+			for _, argument := range arguments {
+				values = append(values, argument.String())
+			}
+			assert.Equal(t, want, strings.Join(values, " "))
+			assert.Equal(t, "[UInt64]", arguments[0].Type().ID())
+			assert.Equal(t, "{String:UFix64}", arguments[2].Type().ID())
+		})
+	}
+
+	arguments, err := decoder.Decode([]byte(`{"ids": [], "owner": "0x1", "royalties": {}, "limit": 10, "tag": ""}`))
+	require.NoError(t, err)
+	assert.Equal(t, cadence.NewOptional(cadence.NewUInt8(10)), arguments[3])
+
+	anyStruct, err := NewArgumentDecoder([]Parameter{{Name: "d", Type: "AnyStruct"}}, defaultOptions)
+	require.NoError(t, err)
+	arguments, err = anyStruct.Decode([]byte(`{"d": -1.5}`))
+	require.NoError(t, err)
+	assert.Equal(t, "Fix64", arguments[0].Type().ID())
+	assert.Equal(t, "-1.50000000", arguments[0].String())
+}
+
+func TestDecodeArgumentsErrors(t *testing.T) {
+	decoder := testArgumentDecoder(t)
+
+	tests := map[string][]ArgumentError{
+		`{"ids": [1, -2, "x"], "owner": 1, "royalties": {"a": "much"}, "limit": 256, "tag": 42}`: {
+			{Field: "ids[1]", Message: "cannot convert -2 to UInt64, it is out of range"},
+			{Field: "ids[2]", Message: "cannot convert x to UInt64: it is not a number"},
+			{Field: "owner", Message: "expected Address, got a number"},
+			{Field: "royalties.a", Message: "cannot convert \"much\" to a fixed point number, it is not a decimal number"},
+			{Field: "limit", Message: "cannot convert 256 to UInt8, it is out of range"},
+			{Field: "tag", Message: "expected String, got a number"},
+		},
+		`{"ids": {}, "royalties": [], "tag": null, "extra": 1}`: {
+			{Field: "owner", Message: "it is required"},
+			{Field: "limit", Message: "it is required"},
+			{Field: "extra", Message: "unknown argument"},
+		},
+		`{"ids": {}, "owner": "0x1", "royalties": [], "limit": null, "tag": null}`: {
+			{Field: "ids", Message: "expected [UInt64], got an object"},
+			{Field: "royalties", Message: "expected {String:UFix64}, got an array"},
+			{Field: "tag", Message: "expected String, got null"},
+		},
+		`[1]`:                               {{Message: "expected 5 arguments, got 1"}},
+		`"ids"`:                             {{Message: "the body must be a json object or array"}},
+		`{"ids": `:                          {{Message: "the body is not valid json: unexpected EOF"}},
+		`[[], "0x1", {}, null, ""] garbage`: {{Message: "the body must be a single json value, there is more after it"}},
+		`{"ids": []} {}`:                    {{Message: "the body must be a single json value, there is more after it"}},
+	}
+	for body, want := range tests {
+		t.Run(body, func(t *testing.T) {
+			_, err := decoder.Decode([]byte(body))
+			var argumentsErr *ArgumentsError
+			require.ErrorAs(t, err, &argumentsErr)
+			assert.Equal(t, want, argumentsErr.Errors)
+		})
+	}
+
+	_, err := NewArgumentDecoder([]Parameter{{Name: "listing", Type: "A.f8d6e0586b0a20c7.Market.Listing"}}, defaultOptions)
+	assert.ErrorContains(t, err, "parameter listing: invalid cadence type")
+}
+
+func TestArgumentsHandler(t *testing.T) {
+	decoder := testArgumentDecoder(t)
+	handler := decoder.Handler(func(w http.ResponseWriter, r *http.Request, arguments []cadence.Value) {
+		_, _ = w.Write([]byte(arguments[4].String()))
+	})
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/listings", strings.NewReader(`[[1], "0x1", {}, null, "foo"]`)))
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, `"foo"`, response.Body.String())
+
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/listings", strings.NewReader(`{"ids": [1], "owner": "0x1", "royalties": {}, "limit": -1, "tag": "foo"}`)))
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error": "invalid arguments", "errors": [{"field": "limit", "message": "cannot convert -1 to UInt8, it is out of range"}]}`, response.Body.String())
+}
+
+func TestArgumentsMiddleware(t *testing.T) {
+	decoder, err := NewArgumentDecoder([]Parameter{{Name: "amount", Type: "UFix64"}}, defaultOptions)
+	require.NoError(t, err)
+
+	var arguments []cadence.Value
+	server := httptest.NewServer(decoder.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arguments = ArgumentsFromRequest(r)
+	})))
+	defer server.Close()
+
+	response, err := http.Post(server.URL, "application/json", strings.NewReader(`{"amount": 1.5}`))
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, []cadence.Value{cadence.UFix64(150_000_000)}, arguments)
+
+	response, err = http.Post(server.URL, "application/json", strings.NewReader(``))
+	require.NoError(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	var body struct{ Errors []ArgumentError }
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&body))
+	assert.Equal(t, []ArgumentError{{Field: "amount", Message: "it is required"}}, body.Errors)
+
+	assert.Nil(t, ArgumentsFromRequest(httptest.NewRequest(http.MethodGet, "/", nil)))
+}
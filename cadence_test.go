@@ -278,6 +278,7 @@ func TestExtractAddresses(t *testing.T) {
 		{autogold.Want("Dict", []string{"0xf8d6e0586b0a20c7", "0x01cf0e2f2f715450"}), dict},
 		{autogold.Want("Array", []string{"0xf8d6e0586b0a20c7", "0x01cf0e2f2f715450"}), array},
 		{autogold.Want("Struct", []string{"0xf8d6e0586b0a20c7"}), strct},
+		{autogold.Want("UntypedStruct", []string{"0x01cf0e2f2f715450"}), cadence.NewStruct([]cadence.Value{address2})},
 	}
 
 	for _, tc := range testCases {
@@ -288,6 +289,70 @@ func TestExtractAddresses(t *testing.T) {
 	}
 }
 
+func TestExtractAddressesWithOption(t *testing.T) {
+	address1, err := hexToAddress("f8d6e0586b0a20c7")
+	require.NoError(t, err)
+	address := *address1
+
+	address2Ptr, err := hexToAddress("01cf0e2f2f715450")
+	require.NoError(t, err)
+	address2 := *address2Ptr
+
+	offerType := &cadence.StructType{
+		Location:            common.NewAddressLocation(nil, common.Address(address), ""),
+		QualifiedIdentifier: "Market.Offer",
+		Fields: []cadence.Field{{
+			Identifier: "buyer",
+			Type:       cadence.AddressType{},
+		}},
+	}
+	offers := cadence.NewArray([]cadence.Value{
+		cadence.NewStruct([]cadence.Value{address}).WithType(offerType),
+		cadence.NewStruct([]cadence.Value{address2}).WithType(offerType),
+	})
+
+	event := cadence.NewEvent([]cadence.Value{
+		offers,
+		cadence.NewDictionary([]cadence.KeyValuePair{{Key: address2, Value: cadenceString("")}}),
+		cadence.NewPathCapability(address, cadence.Path{Domain: common.PathDomainPublic, Identifier: "foo"}, cadence.StringType{}),
+	}).WithType(&cadence.EventType{
+		Location:            common.NewAddressLocation(nil, common.Address(address), ""),
+		QualifiedIdentifier: "Market.Sold",
+		Fields: []cadence.Field{
+			{Identifier: "offers", Type: cadence.NewVariableSizedArrayType(offerType)},
+			{Identifier: "owners", Type: cadence.NewDictionaryType(cadence.AddressType{}, cadence.StringType{})},
+			{Identifier: "cap", Type: cadence.NewCapabilityType(cadence.StringType{})},
+		},
+	})
+
+	t.Run("all", func(t *testing.T) {
+		result := ExtractAddressesWithOption(event, ExtractAddressOptions{})
+		assert.Equal(t, []ExtractedAddress{
+			{Address: "0xf8d6e0586b0a20c7", Path: ".offers[0].buyer", Type: "A.f8d6e0586b0a20c7.Market.Offer"},
+			{Address: "0x01cf0e2f2f715450", Path: ".offers[1].buyer", Type: "A.f8d6e0586b0a20c7.Market.Offer"},
+			{Address: "0x01cf0e2f2f715450", Path: ".owners{0x01cf0e2f2f715450}", Type: "A.f8d6e0586b0a20c7.Market.Sold"},
+			{Address: "0xf8d6e0586b0a20c7", Path: ".cap.address", Type: "A.f8d6e0586b0a20c7.Market.Sold"},
+		}, result)
+	})
+
+	t.Run("dedupe", func(t *testing.T) {
+		result := ExtractAddressesWithOption(event, ExtractAddressOptions{Dedupe: true})
+		assert.Equal(t, []ExtractedAddress{
+			{Address: "0xf8d6e0586b0a20c7", Path: ".offers[0].buyer", Type: "A.f8d6e0586b0a20c7.Market.Offer"},
+			{Address: "0x01cf0e2f2f715450", Path: ".offers[1].buyer", Type: "A.f8d6e0586b0a20c7.Market.Offer"},
+		}, result)
+	})
+
+	t.Run("dictionary with empty key", func(t *testing.T) {
+		dict := cadence.NewDictionary([]cadence.KeyValuePair{{Key: cadenceString(""), Value: address}})
+		assert.Equal(t, []string{"0xf8d6e0586b0a20c7"}, ExtractAddresses(dict))
+	})
+
+	t.Run("no addresses", func(t *testing.T) {
+		assert.Nil(t, ExtractAddresses(cadenceString("foo")))
+	})
+}
+
 func TestIncludeEmptyValues(t *testing.T) {
 	dict := cadence.NewDictionary([]cadence.KeyValuePair{{Key: cadenceString("foo"), Value: cadenceString("")}})
 	array := cadence.NewArray([]cadence.Value{cadenceString("foo"), cadenceString(""), cadenceString("bar")})
@@ -406,6 +471,7 @@ func TestWrapWithComplextTypes(t *testing.T) {
 		{autogold.Want("Event", map[string]interface{}{"<A.f8d6e0586b0a20c7.Contract.TestEvent>": map[string]interface{}{"foo": "Foo"}}), cadenceEvent},
 		{autogold.Want("Resource", map[string]interface{}{"<@A.f8d6e0586b0a20c7.Contract.Resource>": map[string]interface{}{"foo": "foo"}}), resource},
 		{autogold.Want("PathCap", map[string]interface{}{"<Capability<String>>": map[string]interface{}{"address": "0xf8d6e0586b0a20c7", "path": "/storage/foo"}}), pathCap},
+		{autogold.Want("UntypedStruct", map[string]interface{}{"0": "Foo"}), cadence.NewStruct([]cadence.Value{cadenceString("Foo")})},
 	}
 
 	for _, tc := range testCases {
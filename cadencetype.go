@@ -0,0 +1,195 @@
+package underflow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/onflow/cadence"
+)
+
+// the types that can be written with their name in a type expression
+var namedTypes = map[string]cadence.Type{}
+
+func init() {
+	for _, typ := range []cadence.Type{
+		cadence.AnyStructType{},
+		cadence.BoolType{},
+		cadence.StringType{},
+		cadence.CharacterType{},
+		cadence.AddressType{},
+		cadence.IntType{},
+		cadence.Int8Type{},
+		cadence.Int16Type{},
+		cadence.Int32Type{},
+		cadence.Int64Type{},
+		cadence.Int128Type{},
+		cadence.Int256Type{},
+		cadence.UIntType{},
+		cadence.UInt8Type{},
+		cadence.UInt16Type{},
+		cadence.UInt32Type{},
+		cadence.UInt64Type{},
+		cadence.UInt128Type{},
+		cadence.UInt256Type{},
+		cadence.Word8Type{},
+		cadence.Word16Type{},
+		cadence.Word32Type{},
+		cadence.Word64Type{},
+		cadence.Fix64Type{},
+		cadence.UFix64Type{},
+		cadence.PathType{},
+		cadence.StoragePathType{},
+		cadence.PublicPathType{},
+		cadence.PrivatePathType{},
+		cadence.CapabilityPathType{},
+	} {
+		namedTypes[typ.ID()] = typ
+	}
+}
+
+// / Parse a cadence type expression like UInt64, String?, [Address], [UInt8; 4] or {String: UFix64}
+// /  Only types that can be created from go values are supported, composite types are not
+func ParseCadenceType(input string) (cadence.Type, error) {
+	parser := &typeParser{input: input}
+	typ, err := parser.parseType()
+	if err != nil {
+		return nil, err
+	}
+	parser.skipSpace()
+	if parser.pos != len(parser.input) {
+		return nil, parser.errorf("unexpected %q", parser.input[parser.pos:])
+	}
+	return typ, nil
+}
+
+type typeParser struct {
+	input string
+	pos   int
+}
+
+func (p *typeParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid cadence type %q at position %d: %s", p.input, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *typeParser) skipSpace() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// consume the next character if it is c
+func (p *typeParser) accept(c byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.input) && p.input[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *typeParser) expect(c byte) error {
+	if !p.accept(c) {
+		return p.errorf("expected %q", c)
+	}
+	return nil
+}
+
+func (p *typeParser) parseType() (cadence.Type, error) {
+	typ, err := p.parseNonOptionalType()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept('?') {
+		typ = cadence.NewOptionalType(typ)
+	}
+	return typ, nil
+}
+
+func (p *typeParser) parseNonOptionalType() (cadence.Type, error) {
+	switch {
+	case p.accept('['):
+		element, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		if p.accept(';') {
+			size, err := p.parseSize()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(']'); err != nil {
+				return nil, err
+			}
+			return cadence.NewConstantSizedArrayType(size, element), nil
+		}
+		if err := p.expect(']'); err != nil {
+			return nil, err
+		}
+		return cadence.NewVariableSizedArrayType(element), nil
+	case p.accept('{'):
+		key, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(':'); err != nil {
+			return nil, err
+		}
+		element, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect('}'); err != nil {
+			return nil, err
+		}
+		return cadence.NewDictionaryType(key, element), nil
+	}
+
+	name := p.parseIdentifier()
+	if name == "" {
+		return nil, p.errorf("expected a type")
+	}
+	typ, ok := namedTypes[name]
+	if !ok {
+		return nil, p.errorf("unsupported type %s", name)
+	}
+	return typ, nil
+}
+
+func (p *typeParser) parseIdentifier() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.input) {
+		r := rune(p.input[p.pos])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			break
+		}
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *typeParser) parseSize() (uint, error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+		p.pos++
+	}
+	size, err := strconv.ParseUint(p.input[start:p.pos], 10, 32)
+	if err != nil {
+		return 0, p.errorf("expected the size of the array")
+	}
+	return uint(size), nil
+}
+
+// the cadence type in the type= option of a struct tag, nil if there is none
+func tagCadenceType(options []string) (cadence.Type, error) {
+	for _, option := range options {
+		expression, ok := strings.CutPrefix(option, "type=")
+		if ok {
+			return ParseCadenceType(expression)
+		}
+	}
+	return nil, nil
+}
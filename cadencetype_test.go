@@ -0,0 +1,141 @@
+package underflow
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCadenceType(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "UInt64", want: "UInt64"},
+		{input: "String?", want: "String?"},
+		{input: "[Address]", want: "[Address]"},
+		{input: "[UInt8; 4]", want: "[UInt8;4]"},
+		{input: "{String: UFix64}", want: "{String:UFix64}"},
+		{input: "{ String : [Int?] }", want: "{String:[Int?]}"},
+		{input: "StoragePath", want: "StoragePath"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			typ, err := ParseCadenceType(test.input)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, typ.ID())
+		})
+	}
+}
+
+func TestParseCadenceTypeErrors(t *testing.T) {
+	tests := map[string]string{
+		"":                "expected a type",
+		"Foo":             "unsupported type Foo",
+		"[String":         `expected ']'`,
+		"{String UFix64}": `expected ':'`,
+		"[UInt8; x]":      "expected the size of the array",
+		"String String":   `unexpected "String"`,
+	}
+
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseCadenceType(input)
+			assert.ErrorContains(t, err, want)
+		})
+	}
+}
+
+type Debug_Typed struct {
+	ID       int                `cadence:"id,type=UInt64"`
+	Prices   map[string]float64 `cadence:"prices,type={String: UFix64}"`
+	Owners   []string           `cadence:"owners,type=[Address]"`
+	Path     string             `cadence:"path,type=StoragePath"`
+	Nickname *string            `cadence:"nickname,type=String?"`
+	Amount   string             `cadence:"amount,type=Fix64"`
+	Any      interface{}        `cadence:"any,type=AnyStruct"`
+}
+
+func TestTypedInputToCadence(t *testing.T) {
+	value, err := InputToCadence(Debug_Typed{
+		ID:     42,
+		Prices: map[string]float64{"flow": 1.5},
+		Owners: []string{"0xf8d6e0586b0a20c7", "1"},
+		Path:   "/storage/foo",
+		Amount: "-12.5",
+		Any:    "any",
+	}, func(string) (string, error) {
+		return "A.f8d6e0586b0a20c7.Debug.Typed", nil
+	})
+	require.NoError(t, err)
+
+	structValue := value.(cadence.Struct)
+	assert.Equal(t, []string{
+		"42",
+		`{"flow": 1.50000000}`,
+		"[0xf8d6e0586b0a20c7, 0x0000000000000001]",
+		"/storage/foo",
+		"nil",
+		"-12.50000000",
+		`"any"`,
+	}, cadenceStrings(structValue.Fields))
+
+	types := []string{}
+	for _, field := range structValue.StructType.Fields {
+		types = append(types, field.Type.ID())
+	}
+	assert.Equal(t, []string{"UInt64", "{String:UFix64}", "[Address]", "StoragePath", "String?", "Fix64", "AnyStruct"}, types)
+	assert.IsType(t, cadence.UInt64(0), structValue.Fields[0])
+}
+
+func TestTypedInputErrors(t *testing.T) {
+	resolver := func(string) (string, error) {
+		return "A.f8d6e0586b0a20c7.Debug.Bad", nil
+	}
+
+	type Debug_Range struct {
+		Value int `cadence:"value,type=UInt8"`
+	}
+	_, err := InputToCadence(Debug_Range{Value: 256}, resolver)
+	assert.ErrorContains(t, err, "field Value: cannot convert 256 to UInt8, it is out of range")
+
+	_, err = InputToCadence(Debug_Range{Value: -1}, resolver)
+	assert.ErrorContains(t, err, "cannot convert -1 to UInt8, it is out of range")
+
+	type Debug_Lossy struct {
+		Value float64 `cadence:"value,type=Int64"`
+	}
+	_, err = InputToCadence(Debug_Lossy{Value: 1.5}, resolver)
+	assert.ErrorContains(t, err, "it is not a whole number")
+
+	value, err := InputToCadence(Debug_Lossy{Value: 2}, resolver)
+	require.NoError(t, err)
+	assert.Equal(t, cadence.NewInt64(2), value.(cadence.Struct).Fields[0])
+
+	type Debug_Size struct {
+		Value []int `cadence:"value,type=[UInt8; 2]"`
+	}
+	_, err = InputToCadence(Debug_Size{Value: []int{1}}, resolver)
+	assert.ErrorContains(t, err, "cannot convert 1 elements to [UInt8;2]")
+
+	type Debug_Path struct {
+		Value string `cadence:"value,type=PublicPath"`
+	}
+	_, err = InputToCadence(Debug_Path{Value: "/storage/foo"}, resolver)
+	assert.ErrorContains(t, err, "the domain storage is not allowed")
+
+	type Debug_Nil struct {
+		Value *int `cadence:"value,type=Int"`
+	}
+	_, err = InputToCadence(Debug_Nil{}, resolver)
+	assert.ErrorContains(t, err, "cannot convert nil to Int")
+
+	type Debug_Unknown struct {
+		Value int `cadence:"value,type=Foo"`
+	}
+	_, err = InputToCadence(Debug_Unknown{}, resolver)
+	assert.ErrorContains(t, err, "unsupported type Foo")
+}
@@ -0,0 +1,759 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/bjartek/underflow"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/parser"
+)
+
+const genUsage = `Usage: underflow gen [flags] dir|file ...
+
+Generate go code for the contracts, scripts and transactions in .cdc files, the files in a directory are read in name order.
+Contracts get structs, enums and events named Contract_Type, scripts and transactions get their source and functions for
+the arguments and the result. The addresses in flow.json are used for a Resolver and to replace the imports in scripts.
+
+	//go:generate go run github.com/bjartek/underflow/cmd/underflow gen -flow-json ../flow.json ../contracts ../scripts
+
+Flags:
+`
+
+// the name of the file with the contract addresses and the resolver
+const genSharedFile = "underflow.go"
+
+// run the gen subcommand and return the exit code
+func runGen(args []string, stdout io.Writer, stderr io.Writer) int {
+	var flowJson, out, pkg string
+	flags := flag.NewFlagSet("underflow gen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, genUsage)
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&flowJson, "flow-json", "flow.json", "the flow.json file with the contract addresses")
+	flags.StringVar(&out, "out", ".", "the directory the go files are written to")
+	flags.StringVar(&pkg, "pkg", os.Getenv("GOPACKAGE"), "the go package name, the name of the out directory if it is not set")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	if pkg == "" {
+		abs, err := filepath.Abs(out)
+		if err != nil {
+			fmt.Fprintf(stderr, "underflow: %v\n", err)
+			return 2
+		}
+		pkg = strings.ToLower(filepath.Base(abs))
+	}
+	if !token.IsIdentifier(pkg) {
+		fmt.Fprintf(stderr, "underflow: %q is not a valid package name, use -pkg\n", pkg)
+		return 2
+	}
+
+	mapping, err := underflow.NewNetworkMappingFromFlowJson(flowJson)
+	if err != nil {
+		fmt.Fprintf(stderr, "underflow: %v\n", err)
+		return 1
+	}
+
+	paths, err := cadenceFiles(flags.Args())
+	if err != nil {
+		fmt.Fprintf(stderr, "underflow: %v\n", err)
+		return 1
+	}
+
+	files, errs := generate(pkg, mapping, paths)
+	for _, err := range errs {
+		fmt.Fprintf(stderr, "underflow: %v\n", err)
+	}
+	if len(errs) > 0 {
+		return 1
+	}
+
+	for _, file := range files {
+		path := filepath.Join(out, file.name)
+		written, err := writeIfChanged(path, file.code)
+		if err != nil {
+			fmt.Fprintf(stderr, "underflow: %v\n", err)
+			return 1
+		}
+		if written {
+			fmt.Fprintf(stdout, "wrote %s\n", path)
+		}
+	}
+	return 0
+}
+
+// the .cdc files in the directories and the files themselves, sorted so the output is the same every time
+func cadenceFiles(args []string) ([]string, error) {
+	paths := []string{}
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+		entries, err := os.ReadDir(arg)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".cdc") {
+				paths = append(paths, filepath.Join(arg, entry.Name()))
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// the file is only written if the content changed, so running the generator again does not touch the files
+func writeIfChanged(path string, code []byte) (bool, error) {
+	existing, err := os.ReadFile(path)
+	if err == nil && bytes.Equal(existing, code) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(path, code, 0o644)
+}
+
+// a generated go file
+type generatedFile struct {
+	name string
+	code []byte
+}
+
+// a parsed .cdc file
+type cadenceFile struct {
+	path    string
+	code    []byte
+	program *ast.Program
+}
+
+// a composite type declared in a contract
+type compositeInfo struct {
+	goName      string
+	declaration *ast.CompositeDeclaration
+}
+
+type generator struct {
+	pkg     string
+	mapping underflow.NetworkMapping
+	// composites by their qualified identifier like Debug.Foo
+	composites map[string]*compositeInfo
+	// qualified identifiers by go name, two composites like A_B.C and A.B_C would get the same go name
+	goNames map[string]string
+	errs    []error
+}
+
+func (g *generator) errorf(file *cadenceFile, position ast.Position, format string, args ...interface{}) {
+	g.errs = append(g.errs, fmt.Errorf("%s:%d:%d: %s", file.path, position.Line, position.Column+1, fmt.Sprintf(format, args...)))
+}
+
+// generate the go files for the .cdc files, all the errors are returned so they can be fixed in one go
+func generate(pkg string, mapping underflow.NetworkMapping, paths []string) ([]generatedFile, []error) {
+	g := &generator{pkg: pkg, mapping: mapping, composites: map[string]*compositeInfo{}, goNames: map[string]string{}}
+
+	files := []*cadenceFile{}
+	for _, path := range paths {
+		file, err := g.parse(path)
+		if err != nil {
+			g.errs = append(g.errs, err)
+			continue
+		}
+		files = append(files, file)
+	}
+	for _, file := range files {
+		g.register(file)
+	}
+
+	generated := []generatedFile{}
+	names := map[string]string{genSharedFile: "the contract addresses"}
+	for _, file := range files {
+		code, ok := g.file(file)
+		if !ok {
+			continue
+		}
+		name := strings.ToLower(strings.TrimSuffix(filepath.Base(file.path), ".cdc")) + ".go"
+		if other, ok := names[name]; ok {
+			g.errs = append(g.errs, fmt.Errorf("%s:1:1: the go code would be written to %s, it is already used for %s", file.path, name, other))
+			continue
+		}
+		names[name] = file.path
+		generated = append(generated, generatedFile{name: name, code: code})
+	}
+	if len(g.errs) > 0 {
+		return nil, g.errs
+	}
+
+	shared, err := g.format(genSharedFile, g.shared())
+	if err != nil {
+		return nil, []error{err}
+	}
+	return append([]generatedFile{{name: genSharedFile, code: shared}}, generated...), nil
+}
+
+func (g *generator) parse(path string) (*cadenceFile, error) {
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	program, err := parser.ParseProgram(nil, code, parser.Config{})
+	if err != nil {
+		var parseErr parser.Error
+		if errors.As(err, &parseErr) && len(parseErr.Errors) > 0 {
+			first := parseErr.Errors[0]
+			if positioned, ok := first.(ast.HasPosition); ok {
+				position := positioned.StartPosition()
+				return nil, fmt.Errorf("%s:%d:%d: %s", path, position.Line, position.Column+1, first)
+			}
+			return nil, fmt.Errorf("%s: %s", path, first)
+		}
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cadenceFile{path: filepath.ToSlash(path), code: code, program: program}, nil
+}
+
+// register the composites in the contracts so fields can refer to composites in other files
+func (g *generator) register(file *cadenceFile) {
+	for _, declaration := range file.program.CompositeDeclarations() {
+		if declaration.CompositeKind != common.CompositeKindContract {
+			g.errorf(file, declaration.StartPos, "%s %s is declared outside a contract, only types in contracts have a type id that can be resolved", declaration.CompositeKind.Keyword(), declaration.Identifier.Identifier)
+			continue
+		}
+		contract := declaration.Identifier.Identifier
+		for _, composite := range declaration.Members.Composites() {
+			switch composite.CompositeKind {
+			case common.CompositeKindStructure, common.CompositeKindResource, common.CompositeKindEvent, common.CompositeKindEnum:
+			default:
+				continue
+			}
+			identifier := composite.Identifier.Identifier
+			goName := contract + "_" + identifier
+			if other, ok := g.goNames[goName]; ok {
+				g.errorf(file, composite.StartPos, "%s.%s would be generated as %s, which is already used for %s", contract, identifier, goName, other)
+				continue
+			}
+			g.goNames[goName] = contract + "." + identifier
+			g.composites[contract+"."+identifier] = &compositeInfo{
+				goName:      goName,
+				declaration: composite,
+			}
+		}
+	}
+}
+
+// the go code for a file, ok is false if there is nothing to generate
+func (g *generator) file(file *cadenceFile) ([]byte, bool) {
+	body := &codeWriter{imports: map[string]bool{}}
+	for _, declaration := range file.program.CompositeDeclarations() {
+		if declaration.CompositeKind != common.CompositeKindContract {
+			continue
+		}
+		for _, composite := range declaration.Members.Composites() {
+			g.composite(body, file, declaration.Identifier.Identifier, composite)
+		}
+	}
+
+	for _, function := range file.program.FunctionDeclarations() {
+		if function.Identifier.Identifier == "main" {
+			g.script(body, file, function.ParameterList, function.ReturnTypeAnnotation)
+		}
+	}
+	if transaction := file.program.SoleTransactionDeclaration(); transaction != nil {
+		g.script(body, file, transaction.ParameterList, nil)
+	}
+
+	if body.Len() == 0 {
+		return nil, false
+	}
+
+	var code bytes.Buffer
+	fmt.Fprintf(&code, "// Code generated by underflow gen from %s. DO NOT EDIT.\n\npackage %s\n\n", file.path, g.pkg)
+	writeImports(&code, body.imports)
+	code.Write(body.Bytes())
+
+	formatted, err := g.format(file.path, code.Bytes())
+	if err != nil {
+		g.errs = append(g.errs, err)
+		return nil, false
+	}
+	return formatted, true
+}
+
+func (g *generator) format(name string, code []byte) ([]byte, error) {
+	formatted, err := format.Source(code)
+	if err != nil {
+		return nil, fmt.Errorf("cannot format the go code for %s: %w", name, err)
+	}
+	return formatted, nil
+}
+
+func writeImports(w io.Writer, imports map[string]bool) {
+	if len(imports) == 0 {
+		return
+	}
+	// the standard library first like goimports does
+	var std, other []string
+	for path := range imports {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			other = append(other, strconv.Quote(path))
+		} else {
+			std = append(std, strconv.Quote(path))
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	groups := []string{}
+	for _, group := range [][]string{std, other} {
+		if len(group) > 0 {
+			groups = append(groups, strings.Join(group, "\n"))
+		}
+	}
+	fmt.Fprintf(w, "import (\n%s\n)\n\n", strings.Join(groups, "\n\n"))
+}
+
+// the contract addresses from flow.json and the resolver
+func (g *generator) shared() []byte {
+	var code bytes.Buffer
+	fmt.Fprintf(&code, "// Code generated by underflow gen. DO NOT EDIT.\n\npackage %s\n\n", g.pkg)
+	writeImports(&code, map[string]bool{"github.com/bjartek/underflow": true})
+	code.WriteString("// Contracts has the address of every contract in flow.json on every network\nvar Contracts = underflow.NetworkMapping{\n")
+	for _, contract := range sortedKeys(g.mapping) {
+		fmt.Fprintf(&code, "%q: {\n", contract)
+		for _, network := range sortedKeys(g.mapping[contract]) {
+			fmt.Fprintf(&code, "%q: %q,\n", network, g.mapping[contract][network])
+		}
+		code.WriteString("},\n")
+	}
+	code.WriteString("}\n\n")
+
+	// the contract is written out so contracts with an underscore in their name resolve correctly
+	code.WriteString("// Types has the qualified identifier of every generated type by its go name\nvar Types = map[string]string{\n")
+	for _, goName := range sortedKeys(g.goNames) {
+		fmt.Fprintf(&code, "%q: %q,\n", goName, g.goNames[goName])
+	}
+	code.WriteString("}\n\n")
+	code.WriteString("// Resolver resolves the names of the generated types, like Debug_Foo, into type ids on the network\n")
+	code.WriteString("func Resolver(network string) underflow.InputResolver {\nreturn Contracts.TypeResolver(network, Types)\n}\n")
+	return code.Bytes()
+}
+
+// a buffer for the declarations in a file that remembers the imports they need
+type codeWriter struct {
+	bytes.Buffer
+	imports map[string]bool
+}
+
+func (w *codeWriter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(w, format, args...)
+}
+
+func (w *codeWriter) use(packages ...string) {
+	for _, path := range packages {
+		w.imports[path] = true
+	}
+}
+
+func (g *generator) composite(w *codeWriter, file *cadenceFile, contract string, declaration *ast.CompositeDeclaration) {
+	info, ok := g.composites[contract+"."+declaration.Identifier.Identifier]
+	if !ok {
+		return
+	}
+	qualified := contract + "." + declaration.Identifier.Identifier
+
+	if declaration.CompositeKind == common.CompositeKindEnum {
+		g.enum(w, file, info, qualified)
+		return
+	}
+
+	// the fields of an event are the parameters of its initializer
+	type field struct {
+		identifier ast.Identifier
+		annotation *ast.TypeAnnotation
+	}
+	fields := []field{}
+	if declaration.CompositeKind == common.CompositeKindEvent {
+		for _, initializer := range declaration.Members.Initializers() {
+			for _, parameter := range initializer.FunctionDeclaration.ParameterList.Parameters {
+				fields = append(fields, field{identifier: parameter.Identifier, annotation: parameter.TypeAnnotation})
+			}
+		}
+	} else {
+		for _, declared := range declaration.Members.Fields() {
+			fields = append(fields, field{identifier: declared.Identifier, annotation: declared.TypeAnnotation})
+		}
+	}
+
+	w.printf("// %s is the %s %s\ntype %s struct {\n", info.goName, declaration.CompositeKind.Keyword(), qualified, info.goName)
+	names := map[string]string{"TypeID": "the TypeID method"}
+	for _, f := range fields {
+		name := exportedName(f.identifier.Identifier)
+		if other, ok := names[name]; ok {
+			g.errorf(file, f.identifier.Pos, "field %s of %s is named %s in go, it clashes with %s", f.identifier.Identifier, qualified, name, other)
+			continue
+		}
+		names[name] = "field " + f.identifier.Identifier
+
+		typ, ok := g.goType(w, file, contract, f.annotation.Type)
+		if !ok {
+			continue
+		}
+		tag := f.identifier.Identifier
+		if typ.needsTag() {
+			tag += ",type=" + typ.cadence
+		}
+		w.printf("%s %s `cadence:%q`\n", name, typ.expr, tag)
+	}
+	w.printf("}\n\n")
+
+	w.printf("// TypeID is the type id of %s on the network\n", qualified)
+	w.printf("func (%s) TypeID(network string) (string, error) {\nreturn Resolver(network)(%q)\n}\n\n", info.goName, info.goName)
+}
+
+// the raw types an enum can have, the go type and the cadence constructor and type
+var enumRawTypes = map[string][3]string{
+	"Int8":   {"int8", "cadence.NewInt8", "cadence.Int8Type{}"},
+	"Int16":  {"int16", "cadence.NewInt16", "cadence.Int16Type{}"},
+	"Int32":  {"int32", "cadence.NewInt32", "cadence.Int32Type{}"},
+	"Int64":  {"int64", "cadence.NewInt64", "cadence.Int64Type{}"},
+	"UInt8":  {"uint8", "cadence.NewUInt8", "cadence.UInt8Type{}"},
+	"UInt16": {"uint16", "cadence.NewUInt16", "cadence.UInt16Type{}"},
+	"UInt32": {"uint32", "cadence.NewUInt32", "cadence.UInt32Type{}"},
+	"UInt64": {"uint64", "cadence.NewUInt64", "cadence.UInt64Type{}"},
+	"Word8":  {"uint8", "cadence.NewWord8", "cadence.Word8Type{}"},
+	"Word16": {"uint16", "cadence.NewWord16", "cadence.Word16Type{}"},
+	"Word32": {"uint32", "cadence.NewWord32", "cadence.Word32Type{}"},
+	"Word64": {"uint64", "cadence.NewWord64", "cadence.Word64Type{}"},
+}
+
+func (g *generator) enum(w *codeWriter, file *cadenceFile, info *compositeInfo, qualified string) {
+	declaration := info.declaration
+	if len(declaration.Conformances) != 1 {
+		g.errorf(file, declaration.StartPos, "enum %s must have a raw type", qualified)
+		return
+	}
+	rawTypeName := declaration.Conformances[0].String()
+	rawType, ok := enumRawTypes[rawTypeName]
+	if !ok {
+		g.errorf(file, declaration.Conformances[0].StartPosition(), "unsupported raw type %s of enum %s", rawTypeName, qualified)
+		return
+	}
+	w.use("fmt", "github.com/bjartek/underflow", "github.com/onflow/cadence")
+
+	name := info.goName
+	w.printf("// %s is the enum %s\ntype %s %s\n\n", name, qualified, name, rawType[0])
+	if cases := declaration.Members.EnumCases(); len(cases) > 0 {
+		w.printf("const (\n")
+		for i, enumCase := range cases {
+			w.printf("%s%s %s = %d\n", name, exportedName(enumCase.Identifier.Identifier), name, i)
+		}
+		w.printf(")\n\n")
+	}
+
+	w.printf("// TypeID is the type id of %s on the network\n", qualified)
+	w.printf("func (%s) TypeID(network string) (string, error) {\nreturn Resolver(network)(%q)\n}\n\n", name, name)
+
+	w.printf(`// MarshalCadence converts the enum into a cadence enum, the resolver gives its type id
+func (e %[1]s) MarshalCadence(resolver underflow.InputResolver) (cadence.Value, error) {
+	if resolver == nil {
+		return nil, fmt.Errorf("cannot convert %[1]s to cadence without a resolver")
+	}
+	typeID, err := resolver(%[1]q)
+	if err != nil {
+		return nil, err
+	}
+	return cadence.NewEnum([]cadence.Value{%[2]s(%[3]s(e))}).WithType(&cadence.EnumType{
+		QualifiedIdentifier: typeID,
+		RawType:             %[4]s,
+		Fields:              []cadence.Field{{Identifier: "rawValue", Type: %[4]s}},
+	}), nil
+}
+
+// UnmarshalCadence reads the raw value of a cadence enum
+func (e *%[1]s) UnmarshalCadence(value cadence.Value) error {
+	enum, ok := value.(cadence.Enum)
+	if !ok || len(enum.Fields) != 1 {
+		return fmt.Errorf("cannot read %%s into %[1]s, it must be an enum", value)
+	}
+	return underflow.Unmarshal(enum.Fields[0], (*%[3]s)(e))
+}
+
+`, name, rawType[1], rawType[0], rawType[2])
+}
+
+// the go code for a script or transaction, its source and functions for the arguments and the result
+func (g *generator) script(w *codeWriter, file *cadenceFile, parameters *ast.ParameterList, result *ast.TypeAnnotation) {
+	prefix := exportedName(strings.TrimSuffix(filepath.Base(file.path), ".cdc"))
+	if prefix == "" || !unicode.IsLetter([]rune(prefix)[0]) {
+		g.errorf(file, ast.Position{Line: 1}, "cannot name the go code for %s, the file name must start with a letter", file.path)
+		return
+	}
+	name := filepath.Base(file.path)
+
+	w.printf("// %sCode is the source of %s\nconst %sCode = %s\n\n", prefix, name, prefix, goString(string(file.code)))
+	w.printf("// %sSource is %sCode with the imports replaced with the addresses of the contracts on the network\n", prefix, prefix)
+	w.printf("func %sSource(network string) (string, error) {\nreturn Contracts.ReplaceImports(%sCode, network)\n}\n\n", prefix, prefix)
+
+	if parameters != nil && len(parameters.Parameters) > 0 {
+		g.arguments(w, file, prefix, name, parameters.Parameters)
+	}
+
+	if result != nil && !ast.IsEmptyType(result.Type) && result.Type.String() != "Void" {
+		typ, ok := g.goType(w, file, "", result.Type)
+		if !ok {
+			return
+		}
+		w.use("github.com/bjartek/underflow", "github.com/onflow/cadence")
+		w.printf("// %sResult reads the result of %s\n", prefix, name)
+		w.printf("func %sResult(value cadence.Value) (%s, error) {\nvar result %s\nerr := underflow.Unmarshal(value, &result)\nreturn result, err\n}\n\n", prefix, typ.expr, typ.expr)
+	}
+}
+
+func (g *generator) arguments(w *codeWriter, file *cadenceFile, prefix string, name string, parameters []*ast.Parameter) {
+	params := []string{"network string"}
+	conversions := []string{}
+	for i, parameter := range parameters {
+		typ, ok := g.goType(w, file, "", parameter.TypeAnnotation.Type)
+		if !ok {
+			continue
+		}
+		variable := parameterName(parameter.Identifier.Identifier)
+		params = append(params, variable+" "+typ.expr)
+
+		conversion := fmt.Sprintf("underflow.Marshal(%s, resolver)", variable)
+		if typ.cadence != "" {
+			conversion = fmt.Sprintf("underflow.MarshalAs(%s, %q, resolver)", variable, typ.cadence)
+		}
+		conversions = append(conversions, fmt.Sprintf("if values[%d], err = %s; err != nil {\nreturn nil, fmt.Errorf(\"argument %s: %%w\", err)\n}\n", i, conversion, parameter.Identifier.Identifier))
+	}
+
+	w.use("fmt", "github.com/bjartek/underflow", "github.com/onflow/cadence")
+	w.printf("// %sArguments converts the arguments of %s into cadence values, the network is used to resolve the type ids of structs\n", prefix, name)
+	w.printf("func %sArguments(%s) ([]cadence.Value, error) {\n", prefix, strings.Join(params, ", "))
+	w.printf("resolver := underflow.WithResolver(Resolver(network))\nvalues := make([]cadence.Value, %d)\nvar err error\n", len(parameters))
+	for _, conversion := range conversions {
+		w.printf("%s", conversion)
+	}
+	w.printf("return values, nil\n}\n\n")
+}
+
+// a go type for a cadence type
+type goType struct {
+	expr string
+	// the cadence type for the type= tag option, it is empty if it cannot be written as a tag
+	cadence string
+	// the cadence type the go type is converted to without a tag, it is empty for composites
+	converted string
+}
+
+// the type= option is only added if the go type is not converted to the cadence type by itself
+func (t goType) needsTag() bool {
+	return t.cadence != "" && t.cadence != t.converted
+}
+
+// the go type of the primitive cadence types and the cadence type the go type is converted to
+var primitiveGoTypes = map[string][2]string{
+	"String":         {"string", "String"},
+	"Character":      {"string", "String"},
+	"Bool":           {"bool", "Bool"},
+	"Address":        {"underflow.Address", "Address"},
+	"Int":            {"*big.Int", "Int"},
+	"UInt":           {"*big.Int", "Int"},
+	"Int128":         {"*big.Int", "Int"},
+	"Int256":         {"*big.Int", "Int"},
+	"UInt128":        {"*big.Int", "Int"},
+	"UInt256":        {"*big.Int", "Int"},
+	"Int8":           {"int8", "Int8"},
+	"Int16":          {"int16", "Int16"},
+	"Int32":          {"int32", "Int32"},
+	"Int64":          {"int64", "Int64"},
+	"UInt8":          {"uint8", "UInt8"},
+	"UInt16":         {"uint16", "UInt16"},
+	"UInt32":         {"uint32", "UInt32"},
+	"UInt64":         {"uint64", "UInt64"},
+	"Word8":          {"uint8", "UInt8"},
+	"Word16":         {"uint16", "UInt16"},
+	"Word32":         {"uint32", "UInt32"},
+	"Word64":         {"uint64", "UInt64"},
+	"UFix64":         {"float64", "UFix64"},
+	"Fix64":          {"float64", "UFix64"},
+	"Path":           {"string", "String"},
+	"StoragePath":    {"string", "String"},
+	"PublicPath":     {"string", "String"},
+	"PrivatePath":    {"string", "String"},
+	"CapabilityPath": {"string", "String"},
+}
+
+// the go type of a cadence type, composites are looked up in the contract first, ok is false if the type is not supported
+func (g *generator) goType(w *codeWriter, file *cadenceFile, contract string, typ ast.Type) (goType, bool) {
+	unsupported := func() (goType, bool) {
+		g.errorf(file, typ.StartPosition(), "unsupported type %s", typ)
+		return goType{}, false
+	}
+
+	switch typ := typ.(type) {
+	case *ast.NominalType:
+		name := typ.String()
+		if primitive, ok := primitiveGoTypes[name]; ok {
+			switch primitive[0] {
+			case "*big.Int":
+				w.use("math/big")
+			case "underflow.Address":
+				w.use("github.com/bjartek/underflow")
+			}
+			return goType{expr: primitive[0], cadence: name, converted: primitive[1]}, true
+		}
+		if name == "AnyStruct" {
+			return goType{expr: "interface{}", converted: "AnyStruct"}, true
+		}
+		if info, ok := g.composites[contract+"."+name]; ok && len(typ.NestedIdentifiers) == 0 {
+			return goType{expr: info.goName}, true
+		}
+		if info, ok := g.composites[name]; ok {
+			return goType{expr: info.goName}, true
+		}
+		return unsupported()
+
+	case *ast.OptionalType:
+		inner, ok := g.goType(w, file, contract, typ.Type)
+		if !ok {
+			return goType{}, false
+		}
+		result := goType{expr: inner.expr}
+		// nil pointers and interfaces are already nil optionals
+		if inner.expr != "interface{}" && !strings.HasPrefix(inner.expr, "*") {
+			result.expr = "*" + inner.expr
+		}
+		if inner.cadence != "" {
+			result.cadence = inner.cadence + "?"
+		}
+		if inner.converted != "" {
+			result.converted = inner.converted + "?"
+		}
+		if inner.expr == result.expr {
+			// a *big.Int is converted to an Int even if it is an optional
+			result.converted = inner.converted
+		}
+		return result, true
+
+	case *ast.VariableSizedType:
+		inner, ok := g.goType(w, file, contract, typ.Type)
+		if !ok {
+			return goType{}, false
+		}
+		return goType{expr: "[]" + inner.expr, cadence: wrapType("[", inner.cadence, "]"), converted: wrapType("[", inner.converted, "]")}, true
+
+	case *ast.ConstantSizedType:
+		inner, ok := g.goType(w, file, contract, typ.Type)
+		if !ok {
+			return goType{}, false
+		}
+		size := typ.Size.Value.String()
+		return goType{
+			expr:      "[" + size + "]" + inner.expr,
+			cadence:   wrapType("[", inner.cadence, ";"+size+"]"),
+			converted: wrapType("[", inner.converted, ";"+size+"]"),
+		}, true
+
+	case *ast.DictionaryType:
+		key, ok := g.goType(w, file, contract, typ.KeyType)
+		if !ok {
+			return goType{}, false
+		}
+		value, ok := g.goType(w, file, contract, typ.ValueType)
+		if !ok {
+			return goType{}, false
+		}
+		result := goType{expr: "map[" + key.expr + "]" + value.expr}
+		if key.cadence != "" && value.cadence != "" {
+			result.cadence = "{" + key.cadence + ":" + value.cadence + "}"
+		}
+		if key.converted != "" && value.converted != "" {
+			result.converted = "{" + key.converted + ":" + value.converted + "}"
+		}
+		return result, true
+	}
+
+	return unsupported()
+}
+
+func wrapType(open string, inner string, close string) string {
+	if inner == "" {
+		return ""
+	}
+	return open + inner + close
+}
+
+// identifiers that are written in upper case in go
+var initialisms = map[string]bool{"id": true, "uuid": true, "url": true, "uri": true, "nft": true, "ipfs": true}
+
+// an exported go name for a cadence identifier or a file name, like get_foo to GetFoo and id to ID
+func exportedName(identifier string) string {
+	if initialisms[strings.ToLower(identifier)] {
+		return strings.ToUpper(identifier)
+	}
+	var sb strings.Builder
+	upper := true
+	for _, r := range identifier {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// names used in the generated argument functions
+var reservedNames = map[string]bool{
+	"network": true, "resolver": true, "values": true, "err": true,
+	"underflow": true, "cadence": true, "big": true, "fmt": true,
+}
+
+// a parameter name that does not clash with go keywords, predeclared names and the names in the generated code
+func parameterName(identifier string) string {
+	if token.IsKeyword(identifier) || types.Universe.Lookup(identifier) != nil || reservedNames[identifier] {
+		return identifier + "Arg"
+	}
+	return identifier
+}
+
+// a go string literal, raw if possible so the cadence code is readable
+func goString(code string) string {
+	if strings.Contains(code, "`") || strings.Contains(code, "\r") {
+		return strconv.Quote(code)
+	}
+	return "`" + code + "`"
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// run gen in the directory of the example package like go generate does
+func runGenInExample(t *testing.T, out string) (string, string, int) {
+	t.Helper()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(filepath.Join("internal", "example")))
+	defer func() { require.NoError(t, os.Chdir(wd)) }()
+
+	return runCommand(t, "", "gen", "-pkg", "example", "-out", out, "-flow-json", "../../testdata/gen/flow.json",
+		"../../testdata/gen/contracts", "../../testdata/gen/scripts", "../../testdata/gen/transactions")
+}
+
+func TestGenExampleIsUpToDate(t *testing.T) {
+	out := t.TempDir()
+	stdout, stderr, code := runGenInExample(t, out)
+	require.Equal(t, 0, code, stderr)
+	assert.Equal(t, 5, strings.Count(stdout, "wrote "))
+
+	generated, err := os.ReadDir(out)
+	require.NoError(t, err)
+	for _, file := range generated {
+		want, err := os.ReadFile(filepath.Join("internal", "example", file.Name()))
+		require.NoError(t, err, "run go generate ./... to update the example")
+		got, err := os.ReadFile(filepath.Join(out, file.Name()))
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got), "%s is not up to date, run go generate ./...", file.Name())
+	}
+
+	// the files are only written when they change
+	stdout, stderr, code = runGenInExample(t, out)
+	assert.Equal(t, 0, code, stderr)
+	assert.Empty(t, stdout)
+}
+
+func TestGenErrors(t *testing.T) {
+	dir := t.TempDir()
+	flowJson := filepath.Join(dir, "flow.json")
+	require.NoError(t, os.WriteFile(flowJson, []byte(`{"contracts": {}}`), 0o600))
+
+	files := map[string]string{
+		"Broken.cdc": `pub contract Broken {
+	pub struct Foo {
+		pub let ref: &AnyStruct
+		pub let capability: Capability<&AnyStruct>
+		init() {}
+	}
+
+	pub enum Kind: String {
+		pub case a
+	}
+}`,
+		"script.cdc": `pub struct Local {}
+
+pub fun main(callback: ((): Void)) {}`,
+		"syntax.cdc": `pub contract Syntax {
+	pub struct {
+}`,
+		"AB.cdc": `pub contract A {
+	pub struct B_C {}
+}`,
+		"A_B.cdc": `pub contract A_B {
+	pub struct C {}
+}`,
+	}
+	for name, code := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(code), 0o600))
+	}
+
+	out := filepath.Join(dir, "out")
+	stdout, stderr, code := runCommand(t, "", "gen", "-flow-json", flowJson, "-out", out, dir)
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout)
+	for _, want := range []string{
+		"Broken.cdc:3:16: unsupported type &AnyStruct",
+		"Broken.cdc:4:23: unsupported type Capability<&AnyStruct>",
+		"Broken.cdc:8:17: unsupported raw type String of enum Broken.Kind",
+		"script.cdc:1:1: struct Local is declared outside a contract",
+		"script.cdc:3:24: unsupported type ((): Void)",
+		"syntax.cdc:2:",
+		"A_B.cdc:2:2: A_B.C would be generated as A_B_C, which is already used for A.B_C",
+	} {
+		assert.Contains(t, stderr, want)
+	}
+	assert.NoDirExists(t, out, "nothing is written if there are errors")
+}
+
+func TestGenFlags(t *testing.T) {
+	_, stderr, code := runCommand(t, "", "gen")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "Usage: underflow gen")
+
+	_, stderr, code = runCommand(t, "", "gen", "-pkg", "not-valid", "testdata")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `"not-valid" is not a valid package name`)
+}
@@ -0,0 +1,38 @@
+// Code generated by underflow gen from ../../testdata/gen/transactions/buy-listing.cdc. DO NOT EDIT.
+
+package example
+
+import (
+	"fmt"
+
+	"github.com/bjartek/underflow"
+	"github.com/onflow/cadence"
+)
+
+// BuyListingCode is the source of buy-listing.cdc
+const BuyListingCode = `import "Market"
+
+transaction(id: UInt64, amount: UFix64) {
+	prepare(account: AuthAccount) {
+	}
+}
+`
+
+// BuyListingSource is BuyListingCode with the imports replaced with the addresses of the contracts on the network
+func BuyListingSource(network string) (string, error) {
+	return Contracts.ReplaceImports(BuyListingCode, network)
+}
+
+// BuyListingArguments converts the arguments of buy-listing.cdc into cadence values, the network is used to resolve the type ids of structs
+func BuyListingArguments(network string, id uint64, amount float64) ([]cadence.Value, error) {
+	resolver := underflow.WithResolver(Resolver(network))
+	values := make([]cadence.Value, 2)
+	var err error
+	if values[0], err = underflow.MarshalAs(id, "UInt64", resolver); err != nil {
+		return nil, fmt.Errorf("argument id: %w", err)
+	}
+	if values[1], err = underflow.MarshalAs(amount, "UFix64", resolver); err != nil {
+		return nil, fmt.Errorf("argument amount: %w", err)
+	}
+	return values, nil
+}
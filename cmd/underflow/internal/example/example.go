@@ -0,0 +1,4 @@
+// Package example is generated by underflow gen from the cadence files in cmd/underflow/testdata/gen, the tests of gen check that it is up to date
+package example
+
+//go:generate go run github.com/bjartek/underflow/cmd/underflow gen -flow-json ../../testdata/gen/flow.json ../../testdata/gen/contracts ../../testdata/gen/scripts ../../testdata/gen/transactions
@@ -0,0 +1,82 @@
+package example
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/bjartek/underflow"
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testListing(t *testing.T) Market_Listing {
+	seller, err := underflow.ParseAddress("0xf8d6e0586b0a20c7")
+	require.NoError(t, err)
+	return Market_Listing{
+		ID:        42,
+		Seller:    seller,
+		Price:     Market_Price{Amount: 1.5, Change: -0.5, Currency: "$"},
+		Status:    Market_StatusSold,
+		Royalties: map[string]float64{"artist": 0.05},
+		Flags:     []uint8{1},
+		Serial:    big.NewInt(7),
+		Storage:   "/storage/listing",
+		Owner:     Profile_User{Name: "bjartek", Address: seller},
+	}
+}
+
+func TestGeneratedStructs(t *testing.T) {
+	listing := testListing(t)
+
+	value, err := underflow.Marshal(listing, underflow.WithResolver(Resolver("emulator")))
+	require.NoError(t, err)
+	assert.Equal(t, "A.f8d6e0586b0a20c7.Market.Listing", value.Type().ID())
+	assert.Equal(t, `A.f8d6e0586b0a20c7.Market.Listing(id: 42, seller: 0xf8d6e0586b0a20c7, price: A.f8d6e0586b0a20c7.Market.Price(amount: 1.50000000, change: -0.50000000, currency: "$"), status: A.f8d6e0586b0a20c7.Market.Status(rawValue: 1), buyer: nil, royalties: {"artist": 0.05000000}, flags: [1], serial: 7, storage: /storage/listing, owner: A.01cf0e2f2f715450.Profile.User(name: "bjartek", address: 0xf8d6e0586b0a20c7), extra: nil)`, value.String())
+
+	fields := value.(cadence.Struct).Fields
+	assert.Equal(t, "Fix64", fields[2].(cadence.Struct).Fields[1].Type().ID())
+	assert.Equal(t, "[Word8]", fields[6].Type().ID())
+	assert.Equal(t, "Int?", fields[7].Type().ID())
+
+	var result Market_Listing
+	require.NoError(t, underflow.Unmarshal(value, &result))
+	assert.Equal(t, listing, result)
+
+	typeID, err := Market_Listing{}.TypeID("testnet")
+	require.NoError(t, err)
+	assert.Equal(t, "A.0000000000000002.Market.Listing", typeID)
+}
+
+func TestGeneratedEnum(t *testing.T) {
+	_, err := Market_StatusSold.MarshalCadence(nil)
+	assert.ErrorContains(t, err, "without a resolver")
+
+	var status Market_Status
+	assert.ErrorContains(t, status.UnmarshalCadence(cadence.NewUInt8(1)), "it must be an enum")
+}
+
+func TestGeneratedScript(t *testing.T) {
+	source, err := GetListingsSource("emulator")
+	require.NoError(t, err)
+	assert.Contains(t, source, "import Market from 0xf8d6e0586b0a20c7\n")
+
+	_, err = BuyListingSource("mainnet")
+	assert.ErrorContains(t, err, "cannot import Market, the contract has no address on network mainnet")
+
+	arguments, err := GetListingsArguments("emulator", []uint64{1, 2}, Market_StatusListed, "all")
+	require.NoError(t, err)
+	require.Len(t, arguments, 3)
+	assert.Equal(t, "[UInt64]", arguments[0].Type().ID())
+	assert.Equal(t, "A.f8d6e0586b0a20c7.Market.Status", arguments[1].Type().ID())
+	assert.Equal(t, cadence.String("all"), arguments[2])
+
+	_, err = BuyListingArguments("emulator", 1, -1)
+	assert.ErrorContains(t, err, "argument amount:")
+
+	listing, err := underflow.Marshal(testListing(t), underflow.WithResolver(Resolver("emulator")))
+	require.NoError(t, err)
+	result, err := GetListingsResult(cadence.NewDictionary([]cadence.KeyValuePair{{Key: cadence.NewUInt64(42), Value: listing}}))
+	require.NoError(t, err)
+	assert.Equal(t, map[uint64]Market_Listing{42: testListing(t)}, result)
+}
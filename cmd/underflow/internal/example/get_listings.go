@@ -0,0 +1,47 @@
+// Code generated by underflow gen from ../../testdata/gen/scripts/get_listings.cdc. DO NOT EDIT.
+
+package example
+
+import (
+	"fmt"
+
+	"github.com/bjartek/underflow"
+	"github.com/onflow/cadence"
+)
+
+// GetListingsCode is the source of get_listings.cdc
+const GetListingsCode = `import Market from "../contracts/Market.cdc"
+
+pub fun main(ids: [UInt64], status: Market.Status, type: String): {UInt64: Market.Listing} {
+	return {}
+}
+`
+
+// GetListingsSource is GetListingsCode with the imports replaced with the addresses of the contracts on the network
+func GetListingsSource(network string) (string, error) {
+	return Contracts.ReplaceImports(GetListingsCode, network)
+}
+
+// GetListingsArguments converts the arguments of get_listings.cdc into cadence values, the network is used to resolve the type ids of structs
+func GetListingsArguments(network string, ids []uint64, status Market_Status, typeArg string) ([]cadence.Value, error) {
+	resolver := underflow.WithResolver(Resolver(network))
+	values := make([]cadence.Value, 3)
+	var err error
+	if values[0], err = underflow.MarshalAs(ids, "[UInt64]", resolver); err != nil {
+		return nil, fmt.Errorf("argument ids: %w", err)
+	}
+	if values[1], err = underflow.Marshal(status, resolver); err != nil {
+		return nil, fmt.Errorf("argument status: %w", err)
+	}
+	if values[2], err = underflow.MarshalAs(typeArg, "String", resolver); err != nil {
+		return nil, fmt.Errorf("argument type: %w", err)
+	}
+	return values, nil
+}
+
+// GetListingsResult reads the result of get_listings.cdc
+func GetListingsResult(value cadence.Value) (map[uint64]Market_Listing, error) {
+	var result map[uint64]Market_Listing
+	err := underflow.Unmarshal(value, &result)
+	return result, err
+}
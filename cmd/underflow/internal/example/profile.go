@@ -0,0 +1,18 @@
+// Code generated by underflow gen from ../../testdata/gen/contracts/Profile.cdc. DO NOT EDIT.
+
+package example
+
+import (
+	"github.com/bjartek/underflow"
+)
+
+// Profile_User is the struct Profile.User
+type Profile_User struct {
+	Name    string            `cadence:"name"`
+	Address underflow.Address `cadence:"address"`
+}
+
+// TypeID is the type id of Profile.User on the network
+func (Profile_User) TypeID(network string) (string, error) {
+	return Resolver(network)("Profile_User")
+}
@@ -0,0 +1,33 @@
+// Code generated by underflow gen. DO NOT EDIT.
+
+package example
+
+import (
+	"github.com/bjartek/underflow"
+)
+
+// Contracts has the address of every contract in flow.json on every network
+var Contracts = underflow.NetworkMapping{
+	"Market": {
+		"emulator": "f8d6e0586b0a20c7",
+		"testnet":  "0000000000000002",
+	},
+	"Profile": {
+		"emulator": "01cf0e2f2f715450",
+	},
+}
+
+// Types has the qualified identifier of every generated type by its go name
+var Types = map[string]string{
+	"Market_Listing": "Market.Listing",
+	"Market_Price":   "Market.Price",
+	"Market_Receipt": "Market.Receipt",
+	"Market_Sold":    "Market.Sold",
+	"Market_Status":  "Market.Status",
+	"Profile_User":   "Profile.User",
+}
+
+// Resolver resolves the names of the generated types, like Debug_Foo, into type ids on the network
+func Resolver(network string) underflow.InputResolver {
+	return Contracts.TypeResolver(network, Types)
+}
@@ -0,0 +1,398 @@
+// Command underflow converts cadence values between formats and generates go code for cadence files
+//
+//	underflow [flags] [file ...]
+//	underflow gen [flags] dir|file ...
+//
+// It reads JSON-CDC, CCF (binary or hex) or cadence literals like {"foo": 1.5} from the files, or stdin if there are none,
+// and writes them as terse json, yaml, rows with the path and value of every leaf, cadence literals or JSON-CDC.
+// With -jsonl every line of the input is a separate value.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bjartek/underflow"
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/ccf"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"gopkg.in/yaml.v3"
+)
+
+var roundingModes = map[string]big.RoundingMode{
+	"nearest-even":   big.ToNearestEven,
+	"nearest-away":   big.ToNearestAway,
+	"zero":           big.ToZero,
+	"away-from-zero": big.AwayFromZero,
+	"negative-inf":   big.ToNegativeInf,
+	"positive-inf":   big.ToPositiveInf,
+}
+
+var inputFormats = []string{"auto", "json-cdc", "ccf", "cadence"}
+
+var outputFormats = []string{"json", "yaml", "rows", "cadence", "json-cdc"}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+type config struct {
+	in       string
+	out      string
+	jsonl    bool
+	sortKeys bool
+	from, to string
+	// translate address values that are contract accounts, not only the type ids
+	addresses bool
+	mapping   underflow.NetworkMapping
+	opt       underflow.Options
+}
+
+// run the command and return the exit code, 1 if a value could not be converted and 2 if the flags are wrong
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == "gen" {
+		return runGen(args[1:], stdout, stderr)
+	}
+
+	cfg, files, err := parseFlags(args, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "underflow: %v\n", err)
+		return 2
+	}
+
+	w := bufio.NewWriter(stdout)
+	defer w.Flush()
+
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	failed := false
+	written := 0
+	for _, file := range files {
+		data, err := readInput(file, stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "underflow: %v\n", err)
+			failed = true
+			continue
+		}
+
+		inputs := []input{{name: file, data: data}}
+		if cfg.jsonl {
+			inputs = splitLines(file, data)
+		}
+		for _, in := range inputs {
+			if err := cfg.convert(w, in, written); err != nil {
+				fmt.Fprintf(stderr, "underflow: %s: %v\n", in, err)
+				failed = true
+				continue
+			}
+			written++
+		}
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+func parseFlags(args []string, stderr io.Writer) (*config, []string, error) {
+	cfg := &config{}
+	var addressBook, flowJson, rounding string
+
+	flags := flag.NewFlagSet("underflow", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: underflow [flags] [file ...]\n       underflow gen [flags] dir|file ...\n\nConvert cadence values read from the files, or stdin, between formats. See underflow gen -h for generating go code.\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&cfg.in, "in", "auto", "the input format: "+strings.Join(inputFormats, ", "))
+	flags.StringVar(&cfg.out, "out", "json", "the output format: "+strings.Join(outputFormats, ", "))
+	flags.BoolVar(&cfg.jsonl, "jsonl", false, "every line of the input is a separate value, json is written one value per line")
+	flags.BoolVar(&cfg.opt.IncludeEmptyValues, "include-empty", false, "keep empty values in json, yaml and rows output")
+	flags.BoolVar(&cfg.opt.WrapWithComplexTypes, "wrap-complex", false, "wrap structs and paths with their type in json and yaml output")
+	flags.BoolVar(&cfg.opt.UseStringForFixedNumbers, "string-fixed", false, "write UFix64 and Fix64 as strings in json and yaml output")
+	flags.StringVar(&addressBook, "address-book", "", "a flow.json file, addresses of its accounts are written with the account name")
+	flags.BoolVar(&cfg.opt.AddressBookIncludeHex, "address-book-hex", false, "write addresses in the address book as name (0x...)")
+	flags.StringVar(&flowJson, "flow-json", "flow.json", "the flow.json file with the contracts used by -from and -to")
+	flags.StringVar(&cfg.from, "from", "", "the network the type ids in the input are from")
+	flags.StringVar(&cfg.to, "to", "", "translate type ids to this network")
+	flags.BoolVar(&cfg.addresses, "translate-addresses", false, "with -from and -to also translate addresses of contract accounts in cadence and json-cdc output, user data included")
+	flags.StringVar(&rounding, "float-rounding", "nearest-even", "how cadence literals with more than 8 decimals are rounded: "+strings.Join(sortedKeys(roundingModes), ", "))
+	flags.BoolVar(&cfg.sortKeys, "sort-keys", false, "sort the keys of dictionaries in the input, cadence literals are always sorted")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if !contains(inputFormats, cfg.in) {
+		return nil, nil, fmt.Errorf("unknown input format %q, use one of %s", cfg.in, strings.Join(inputFormats, ", "))
+	}
+	if !contains(outputFormats, cfg.out) {
+		return nil, nil, fmt.Errorf("unknown output format %q, use one of %s", cfg.out, strings.Join(outputFormats, ", "))
+	}
+
+	mode, ok := roundingModes[rounding]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown float rounding %q, use one of %s", rounding, strings.Join(sortedKeys(roundingModes), ", "))
+	}
+	cfg.opt.FloatRounding = mode
+	// dictionaries in cadence literals are ordered the same way as -sort-keys orders the input
+	cfg.opt.DictionaryKeyLess = underflow.CadenceKeyLess
+
+	if addressBook != "" {
+		book, err := underflow.NewAddressBookFromFlowJson(addressBook)
+		if err != nil {
+			return nil, nil, err
+		}
+		cfg.opt.AddressBook = book
+	}
+
+	if (cfg.from == "") != (cfg.to == "") {
+		return nil, nil, fmt.Errorf("-from and -to must be used together")
+	}
+	if cfg.addresses && cfg.from == "" {
+		return nil, nil, fmt.Errorf("-translate-addresses must be used with -from and -to")
+	}
+	if cfg.from != "" {
+		mapping, err := underflow.NewNetworkMappingFromFlowJson(flowJson)
+		if err != nil {
+			return nil, nil, err
+		}
+		cfg.mapping = mapping
+		cfg.opt.TranslateTypeID = mapping.TypeIDTranslator(cfg.from, cfg.to)
+	}
+
+	return cfg, flags.Args(), nil
+}
+
+// a single value to convert, line is 0 if it is the whole file
+type input struct {
+	name string
+	line int
+	data []byte
+}
+
+func (in input) String() string {
+	if in.line == 0 {
+		return in.name
+	}
+	return fmt.Sprintf("%s:%d", in.name, in.line)
+}
+
+func readInput(file string, stdin io.Reader) ([]byte, error) {
+	if file == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(file)
+}
+
+// every line that is not blank is an input
+func splitLines(name string, data []byte) []input {
+	inputs := []input{}
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) > 0 {
+			inputs = append(inputs, input{name: name, line: i + 1, data: line})
+		}
+	}
+	return inputs
+}
+
+func (cfg *config) convert(w io.Writer, in input, index int) error {
+	value, err := cfg.decode(in.data)
+	if err != nil {
+		return err
+	}
+
+	if cfg.sortKeys {
+		value, err = underflow.Transform(value, sortDictionary)
+		if err != nil {
+			return err
+		}
+	}
+
+	// json, yaml and rows translate the type ids with the options, the other formats write the type of the value
+	if cfg.mapping != nil && (cfg.out == "cadence" || cfg.out == "json-cdc") {
+		if cfg.addresses {
+			value, err = cfg.mapping.TranslateValueAndAddresses(value, cfg.from, cfg.to)
+		} else {
+			value, err = cfg.mapping.TranslateValue(value, cfg.from, cfg.to)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return cfg.encode(w, value, in, index)
+}
+
+func (cfg *config) decode(data []byte) (cadence.Value, error) {
+	switch cfg.in {
+	case "json-cdc":
+		return jsoncdc.Decode(nil, data)
+	case "ccf":
+		return decodeCCF(data)
+	case "cadence":
+		return underflow.ParseCadenceLiteralWithOption(string(data), cfg.opt)
+	}
+
+	// a JSON-CDC value is always a json object and a dictionary literal also starts with {, so both are tried
+	text := bytes.TrimSpace(data)
+	if bytes.HasPrefix(text, []byte("{")) {
+		if value, err := jsoncdc.Decode(nil, text); err == nil {
+			return value, nil
+		}
+	}
+	if value, err := decodeCCF(data); err == nil {
+		return value, nil
+	}
+	value, err := underflow.ParseCadenceLiteralWithOption(string(text), cfg.opt)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the input as JSON-CDC, CCF or a cadence literal: %w", err)
+	}
+	return value, nil
+}
+
+// ccf is binary, but it is often copied around as hex
+func decodeCCF(data []byte) (cadence.Value, error) {
+	text := strings.TrimPrefix(string(bytes.TrimSpace(data)), "0x")
+	if decoded, err := hex.DecodeString(text); err == nil {
+		data = decoded
+	}
+	return ccf.Decode(nil, data)
+}
+
+func sortDictionary(_ underflow.ValuePath, value cadence.Value) (cadence.Value, error) {
+	dictionary, ok := value.(cadence.Dictionary)
+	if !ok {
+		return value, nil
+	}
+	pairs := append([]cadence.KeyValuePair{}, dictionary.Pairs...)
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return underflow.CadenceKeyLess(pairs[i].Key, pairs[j].Key)
+	})
+	sorted := cadence.NewDictionary(pairs)
+	if dictionary.DictionaryType != nil {
+		sorted = sorted.WithType(dictionary.DictionaryType)
+	}
+	return sorted, nil
+}
+
+func (cfg *config) encode(w io.Writer, value cadence.Value, in input, index int) error {
+	switch cfg.out {
+	case "json":
+		encoder := json.NewEncoder(w)
+		// wrapped types are written as <type id>
+		encoder.SetEscapeHTML(false)
+		if !cfg.jsonl {
+			encoder.SetIndent("", "    ")
+		}
+		return encoder.Encode(underflow.CadenceValueToInterfaceWithOption(value, cfg.opt))
+	case "yaml":
+		encoded, err := yaml.Marshal(underflow.CadenceValueToInterfaceWithOption(value, cfg.opt))
+		if err != nil {
+			return err
+		}
+		if index > 0 {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		_, err = w.Write(encoded)
+		return err
+	case "rows":
+		return cfg.writeRows(w, value, in)
+	case "cadence":
+		_, err := fmt.Fprintln(w, value.String())
+		return err
+	case "json-cdc":
+		encoded, err := jsoncdc.Encode(value)
+		if err != nil {
+			return err
+		}
+		// JSON-CDC already ends with a newline
+		_, err = w.Write(encoded)
+		return err
+	}
+	return fmt.Errorf("unknown output format %q", cfg.out)
+}
+
+// write path<TAB>value for every value without children, with -jsonl the rows start with the line number
+func (cfg *config) writeRows(w io.Writer, value cadence.Value, in input) error {
+	prefix := ""
+	if in.line > 0 {
+		prefix = fmt.Sprintf("%d\t", in.line)
+	}
+
+	// a value is a leaf if nothing deeper is entered before it is left, optionals do not add to the path
+	var leaf cadence.Value
+	var leafPath underflow.ValuePath
+	return underflow.Walk(value, underflow.VisitorFuncs{
+		EnterFunc: func(path underflow.ValuePath, value cadence.Value) error {
+			if last, ok := path.Last(); ok && last.Kind == underflow.PathKey {
+				return underflow.SkipValue
+			}
+			leaf, leafPath = value, path
+			return nil
+		},
+		LeaveFunc: func(path underflow.ValuePath, value cadence.Value) error {
+			if leaf == nil || len(path) != len(leafPath) {
+				return nil
+			}
+			current := leaf
+			leaf = nil
+
+			terse := underflow.CadenceValueToInterfaceWithOption(current, cfg.opt)
+			if terse == nil && !cfg.opt.IncludeEmptyValues {
+				return nil
+			}
+			rendered, ok := terse.(string)
+			if !ok {
+				encoded, err := json.Marshal(terse)
+				if err != nil {
+					return err
+				}
+				rendered = string(encoded)
+			}
+
+			name := path.String()
+			if name == "" {
+				name = "."
+			}
+			_, err := fmt.Fprintf(w, "%s%s\t%s\n", prefix, name, rendered)
+			return err
+		},
+	})
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
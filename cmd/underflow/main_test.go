@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/ccf"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const deposit = `A.f8d6e0586b0a20c7.Debug.Deposit(amount: 1.5, to: 0x01cf0e2f2f715450, tags: {"b": 2, "a": 1})`
+
+func runCommand(t *testing.T, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestOutputFormats(t *testing.T) {
+	tests := map[string]string{
+		"json": `{
+    "amount": 1.5,
+    "tags": {
+        "a": 1,
+        "b": 2
+    },
+    "to": "0x01cf0e2f2f715450"
+}
+`,
+		"yaml": `amount: 1.5
+tags:
+    a: 1
+    b: 2
+to: "0x01cf0e2f2f715450"
+`,
+		"rows": `.amount	1.5
+.to	0x01cf0e2f2f715450
+.tags["a"]	1
+.tags["b"]	2
+`,
+		"cadence": `A.f8d6e0586b0a20c7.Debug.Deposit(amount: 1.50000000, to: 0x01cf0e2f2f715450, tags: {"a": 1, "b": 2})
+`,
+	}
+	for out, want := range tests {
+		t.Run(out, func(t *testing.T) {
+			stdout, stderr, code := runCommand(t, deposit, "-out", out)
+			assert.Equal(t, 0, code, stderr)
+			assert.Equal(t, want, stdout)
+		})
+	}
+}
+
+func TestInputFormats(t *testing.T) {
+	value := cadence.NewArray([]cadence.Value{cadence.String("foo"), cadence.UFix64(150_000_000)}).
+		WithType(cadence.NewVariableSizedArrayType(cadence.AnyStructType{}))
+	jsonCdc, err := jsoncdc.Encode(value)
+	require.NoError(t, err)
+	encodedCCF, err := ccf.Encode(value)
+	require.NoError(t, err)
+
+	tests := map[string][]string{
+		"json-cdc":    {string(jsonCdc)},
+		"ccf":         {string(encodedCCF)},
+		"ccf as hex":  {hex.EncodeToString(encodedCCF)},
+		"cadence":     {`["foo", 1.5]`},
+		"forced json": {string(jsonCdc), "-in", "json-cdc"},
+		"forced ccf":  {string(encodedCCF), "-in", "ccf"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			stdout, stderr, code := runCommand(t, test[0], append([]string{"-out", "cadence"}, test[1:]...)...)
+			assert.Equal(t, 0, code, stderr)
+			assert.Equal(t, "[\"foo\", 1.50000000]\n", stdout)
+		})
+	}
+}
+
+func TestJsonl(t *testing.T) {
+	input := `{"type":"String","value":"foo"}
+
+1.5
+not a value
+[1, 2]
+`
+	stdout, stderr, code := runCommand(t, input, "-jsonl", "-string-fixed")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "\"foo\"\n\"1.50000000\"\n[1,2]\n", stdout)
+	assert.Contains(t, stderr, "underflow: -:4: cannot read the input as JSON-CDC, CCF or a cadence literal")
+
+	stdout, _, code = runCommand(t, "[1]\n[2, 3]\n", "-jsonl", "-out", "rows")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "1\t[0]\t1\n2\t[0]\t2\n2\t[1]\t3\n", stdout)
+
+	stdout, _, code = runCommand(t, "1\n2\n", "-jsonl", "-out", "yaml")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "1\n---\n2\n", stdout)
+}
+
+func TestOptionFlags(t *testing.T) {
+	dir := t.TempDir()
+	flowJson := filepath.Join(dir, "flow.json")
+	require.NoError(t, os.WriteFile(flowJson, []byte(`{
+  "contracts": {"Debug": {"source": "./Debug.cdc", "aliases": {"emulator": "f8d6e0586b0a20c7", "testnet": "0000000000000002"}}},
+  "accounts": {"emulator-first": {"address": "01cf0e2f2f715450", "key": ""}}
+}`), 0o600))
+	input := filepath.Join(dir, "deposit.cdc")
+	require.NoError(t, os.WriteFile(input, []byte(deposit), 0o600))
+
+	stdout, stderr, code := runCommand(t, "", "-address-book", flowJson, "-address-book-hex", "-wrap-complex", "-jsonl", input)
+	assert.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, `"to":"emulator-first (0x01cf0e2f2f715450)"`)
+	assert.Contains(t, stdout, `{"<A.f8d6e0586b0a20c7.Debug.Deposit>":{`)
+
+	stdout, stderr, code = runCommand(t, "", "-flow-json", flowJson, "-from", "emulator", "-to", "testnet", "-wrap-complex", "-jsonl", input)
+	assert.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, `{"<A.0000000000000002.Debug.Deposit>":{`)
+
+	stdout, stderr, code = runCommand(t, "", "-flow-json", flowJson, "-from", "emulator", "-to", "testnet", "-out", "cadence", input)
+	assert.Equal(t, 0, code, stderr)
+	assert.True(t, strings.HasPrefix(stdout, "A.0000000000000002.Debug.Deposit("), stdout)
+
+	stdout, stderr, code = runCommand(t, "0xf8d6e0586b0a20c7", "-flow-json", flowJson, "-from", "emulator", "-to", "testnet", "-out", "cadence")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "0xf8d6e0586b0a20c7\n", stdout)
+
+	stdout, stderr, code = runCommand(t, "0xf8d6e0586b0a20c7", "-flow-json", flowJson, "-from", "emulator", "-to", "testnet", "-translate-addresses", "-out", "cadence")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "0x0000000000000002\n", stdout)
+
+	stdout, _, code = runCommand(t, `{"a": ""}`, "-include-empty", "-jsonl")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "{\"a\":\"\"}\n", stdout)
+
+	stdout, _, code = runCommand(t, `0.123456789`, "-float-rounding", "zero", "-out", "cadence")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "0.12345678\n", stdout)
+}
+
+func TestSortKeys(t *testing.T) {
+	value := cadence.NewDictionary([]cadence.KeyValuePair{
+		{Key: cadence.String("b"), Value: cadence.NewInt(2)},
+		{Key: cadence.String("a"), Value: cadence.NewInt(1)},
+	}).WithType(cadence.NewDictionaryType(cadence.StringType{}, cadence.IntType{}))
+	encoded, err := jsoncdc.Encode(value)
+	require.NoError(t, err)
+
+	stdout, _, _ := runCommand(t, string(encoded), "-out", "cadence")
+	assert.Equal(t, "{\"b\": 2, \"a\": 1}\n", stdout)
+
+	stdout, _, _ = runCommand(t, string(encoded), "-out", "cadence", "-sort-keys")
+	assert.Equal(t, "{\"a\": 1, \"b\": 2}\n", stdout)
+}
+
+func TestFlagErrors(t *testing.T) {
+	tests := map[string][]string{
+		"unknown output format":   {"-out", "xml"},
+		"unknown input format":    {"-in", "xml"},
+		"unknown float rounding":  {"-float-rounding", "up"},
+		"must be used together":   {"-from", "emulator"},
+		"must be used with -from": {"-translate-addresses"},
+	}
+	for want, args := range tests {
+		t.Run(want, func(t *testing.T) {
+			_, stderr, code := runCommand(t, "", args...)
+			assert.Equal(t, 2, code)
+			assert.Contains(t, stderr, want)
+		})
+	}
+
+	_, stderr, code := runCommand(t, "", "missing.json")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "missing.json")
+}
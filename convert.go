@@ -0,0 +1,329 @@
+package underflow
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+)
+
+// integerType describes the range of a cadence integer type and how to create it, a nil bound is unbounded
+type integerType struct {
+	min    *big.Int
+	max    *big.Int
+	create func(*big.Int) (cadence.Value, error)
+}
+
+func signedRange(bits uint) (*big.Int, *big.Int) {
+	max := new(big.Int).Lsh(big.NewInt(1), bits-1)
+	min := new(big.Int).Neg(max)
+	return min, max.Sub(max, big.NewInt(1))
+}
+
+func unsignedMax(bits uint) *big.Int {
+	max := new(big.Int).Lsh(big.NewInt(1), bits)
+	return max.Sub(max, big.NewInt(1))
+}
+
+func newIntegerType(signed bool, bits uint, create func(*big.Int) (cadence.Value, error)) integerType {
+	if signed {
+		min, max := signedRange(bits)
+		return integerType{min: min, max: max, create: create}
+	}
+	return integerType{min: big.NewInt(0), max: unsignedMax(bits), create: create}
+}
+
+var integerTypes = map[string]integerType{
+	"Int": {create: func(i *big.Int) (cadence.Value, error) { return cadence.NewIntFromBig(i), nil }},
+	"Int8": newIntegerType(true, 8, func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewInt8(int8(i.Int64())), nil
+	}),
+	"Int16": newIntegerType(true, 16, func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewInt16(int16(i.Int64())), nil
+	}),
+	"Int32": newIntegerType(true, 32, func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewInt32(int32(i.Int64())), nil
+	}),
+	"Int64": newIntegerType(true, 64, func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewInt64(i.Int64()), nil
+	}),
+	"Int128": newIntegerType(true, 128, func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewInt128FromBig(i)
+	}),
+	"Int256": newIntegerType(true, 256, func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewInt256FromBig(i)
+	}),
+	"UInt": {min: big.NewInt(0), create: func(i *big.Int) (cadence.Value, error) { return cadence.NewUIntFromBig(i) }},
+	"UInt8": newIntegerType(false, 8, func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewUInt8(uint8(i.Uint64())), nil
+	}),
+	"UInt16": newIntegerType(false, 16, func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewUInt16(uint16(i.Uint64())), nil
+	}),
+	"UInt32": newIntegerType(false, 32, func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewUInt32(uint32(i.Uint64())), nil
+	}),
+	"UInt64": newIntegerType(false, 64, func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewUInt64(i.Uint64()), nil
+	}),
+	"UInt128": newIntegerType(false, 128, func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewUInt128FromBig(i)
+	}),
+	"UInt256": newIntegerType(false, 256, func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewUInt256FromBig(i)
+	}),
+	"Word8": newIntegerType(false, 8, func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewWord8(uint8(i.Uint64())), nil
+	}),
+	"Word16": newIntegerType(false, 16, func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewWord16(uint16(i.Uint64())), nil
+	}),
+	"Word32": newIntegerType(false, 32, func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewWord32(uint32(i.Uint64())), nil
+	}),
+	"Word64": newIntegerType(false, 64, func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewWord64(i.Uint64()), nil
+	}),
+}
+
+// / Convert a go value into a value of the given cadence type, numbers are range checked and lossy conversions are errors
+func convertToCadenceType(value reflect.Value, target cadence.Type, resolver InputResolver, opt Options) (cadence.Value, error) {
+	if optional, ok := target.(*cadence.OptionalType); ok {
+		if isNilValue(value) {
+			return cadence.NewOptional(nil), nil
+		}
+		inner, err := convertToCadenceType(indirectValue(value), optional.Type, resolver, opt)
+		if err != nil {
+			return nil, err
+		}
+		return cadence.NewOptional(inner), nil
+	}
+
+	if isNilValue(value) {
+		return nil, fmt.Errorf("cannot convert nil to %s", target.ID())
+	}
+	value = indirectValue(value)
+
+	if _, ok := target.(cadence.AnyStructType); ok {
+		return reflectToCadence(value, resolver, opt, nil)
+	}
+
+	if integer, ok := integerTypes[target.ID()]; ok {
+		i, err := reflectToBigInt(value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %v to %s: %w", value, target.ID(), err)
+		}
+		if (integer.min != nil && i.Cmp(integer.min) < 0) || (integer.max != nil && i.Cmp(integer.max) > 0) {
+			return nil, fmt.Errorf("cannot convert %s to %s, it is out of range", i, target.ID())
+		}
+		return integer.create(i)
+	}
+
+	switch target := target.(type) {
+	case cadence.UFix64Type, cadence.Fix64Type:
+		decimal, err := reflectToDecimal(value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %v to %s: %w", value, target.ID(), err)
+		}
+		_, signed := target.(cadence.Fix64Type)
+		return decimalToFixedPoint(decimal, signed, opt.FloatRounding)
+	case cadence.StringType:
+		if value.Kind() != reflect.String {
+			return nil, fmt.Errorf("cannot convert %s to String", value.Type())
+		}
+		return cadence.NewString(value.String())
+	case cadence.CharacterType:
+		if value.Kind() != reflect.String {
+			return nil, fmt.Errorf("cannot convert %s to Character", value.Type())
+		}
+		return cadence.NewCharacter(value.String())
+	case cadence.BoolType:
+		if value.Kind() != reflect.Bool {
+			return nil, fmt.Errorf("cannot convert %s to Bool", value.Type())
+		}
+		return cadence.NewBool(value.Bool()), nil
+	case cadence.AddressType:
+		return reflectToAddress(value)
+	case cadence.PathType, cadence.StoragePathType, cadence.PublicPathType, cadence.PrivatePathType, cadence.CapabilityPathType:
+		if value.Kind() != reflect.String {
+			return nil, fmt.Errorf("cannot convert %s to %s", value.Type(), target.ID())
+		}
+		return parsePath(value.String(), target)
+	case *cadence.VariableSizedArrayType:
+		values, err := convertElements(value, target.ElementType, resolver, opt)
+		if err != nil {
+			return nil, err
+		}
+		return cadence.NewArray(values).WithType(target), nil
+	case *cadence.ConstantSizedArrayType:
+		values, err := convertElements(value, target.ElementType, resolver, opt)
+		if err != nil {
+			return nil, err
+		}
+		if uint(len(values)) != target.Size {
+			return nil, fmt.Errorf("cannot convert %d elements to %s", len(values), target.ID())
+		}
+		return cadence.NewArray(values).WithType(target), nil
+	case *cadence.DictionaryType:
+		if value.Kind() != reflect.Map {
+			return nil, fmt.Errorf("cannot convert %s to %s", value.Type(), target.ID())
+		}
+		pairs := []cadence.KeyValuePair{}
+		iter := value.MapRange()
+		for iter.Next() {
+			key, err := convertToCadenceType(iter.Key(), target.KeyType, resolver, opt)
+			if err != nil {
+				return nil, err
+			}
+			val, err := convertToCadenceType(iter.Value(), target.ElementType, resolver, opt)
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, cadence.KeyValuePair{Key: key, Value: val})
+		}
+		sortKeyValuePairs(pairs, opt.keyLess())
+		return cadence.NewDictionary(pairs).WithType(target), nil
+	}
+
+	return nil, fmt.Errorf("cannot convert %s to %s, the type is not supported", value.Type(), target.ID())
+}
+
+func convertElements(value reflect.Value, elementType cadence.Type, resolver InputResolver, opt Options) ([]cadence.Value, error) {
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, fmt.Errorf("cannot convert %s to an array", value.Type())
+	}
+	values := make([]cadence.Value, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		element, err := convertToCadenceType(value.Index(i), elementType, resolver, opt)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		values[i] = element
+	}
+	return values, nil
+}
+
+// a nil pointer or interface
+func isNilValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return value.IsNil()
+	}
+	return false
+}
+
+// follow pointers and interfaces to the value they point to, big.Int pointers are kept
+func indirectValue(value reflect.Value) reflect.Value {
+	for (value.Kind() == reflect.Pointer && value.Type() != bigIntPtrType) || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return value
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// an integer from a go value, floats and decimal strings must not have decimals
+func reflectToBigInt(value reflect.Value) (*big.Int, error) {
+	switch value.Type() {
+	case bigIntPtrType:
+		return new(big.Int).Set(value.Interface().(*big.Int)), nil
+	case bigIntType:
+		i := value.Interface().(big.Int)
+		return &i, nil
+	}
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(value.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Int).SetUint64(value.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		f := value.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) || f != math.Trunc(f) {
+			return nil, fmt.Errorf("it is not a whole number")
+		}
+		i, _ := big.NewFloat(f).Int(nil)
+		return i, nil
+	case reflect.String:
+		rat, ok := new(big.Rat).SetString(value.String())
+		if !ok {
+			return nil, fmt.Errorf("it is not a number")
+		}
+		if !rat.IsInt() {
+			return nil, fmt.Errorf("it is not a whole number")
+		}
+		return rat.Num(), nil
+	}
+	return nil, fmt.Errorf("%s is not a number", value.Type())
+}
+
+// a decimal string from a go value
+func reflectToDecimal(value reflect.Value) (string, error) {
+	switch value.Type() {
+	case bigIntPtrType:
+		return value.Interface().(*big.Int).String(), nil
+	case bigIntType:
+		i := value.Interface().(big.Int)
+		return i.String(), nil
+	}
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprint(value.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return fmt.Sprint(value.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		f := value.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", fmt.Errorf("it is not a number")
+		}
+		return strconv.FormatFloat(f, 'f', -1, value.Type().Bits()), nil
+	case reflect.String:
+		return value.String(), nil
+	}
+	return "", fmt.Errorf("%s is not a number", value.Type())
+}
+
+func reflectToAddress(value reflect.Value) (cadence.Value, error) {
+	if value.Type() == addressType {
+		return value.Interface().(Address).Cadence(), nil
+	}
+	if value.Kind() != reflect.String {
+		return nil, fmt.Errorf("cannot convert %s to Address", value.Type())
+	}
+	address, err := ParseAddress(value.String())
+	if err != nil {
+		return nil, err
+	}
+	return address.Cadence(), nil
+}
+
+// parse a path like /storage/foo, the domain must match the path type
+func parsePath(input string, target cadence.Type) (cadence.Value, error) {
+	domainName, identifier, ok := strings.Cut(strings.TrimPrefix(input, "/"), "/")
+	if !ok || !strings.HasPrefix(input, "/") {
+		return nil, fmt.Errorf("cannot convert %q to %s, paths look like /storage/foo", input, target.ID())
+	}
+
+	domain := common.PathDomainFromIdentifier(domainName)
+	allowed := map[string][]common.PathDomain{
+		"StoragePath":    {common.PathDomainStorage},
+		"PublicPath":     {common.PathDomainPublic},
+		"PrivatePath":    {common.PathDomainPrivate},
+		"CapabilityPath": {common.PathDomainPublic, common.PathDomainPrivate},
+		"Path":           {common.PathDomainStorage, common.PathDomainPublic, common.PathDomainPrivate},
+	}[target.ID()]
+
+	for _, d := range allowed {
+		if d == domain {
+			return cadence.NewPath(domain, identifier)
+		}
+	}
+	return nil, fmt.Errorf("cannot convert %q to %s, the domain %s is not allowed", input, target.ID(), domainName)
+}
@@ -0,0 +1,366 @@
+package underflow
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"time"
+
+	"github.com/fatih/structtag"
+	"github.com/onflow/cadence"
+)
+
+// / Read a cadence value into the go value v points to, it is the reverse of InputToCadence and uses the same struct tags
+// /  Composite fields are matched by name, fields that are not in the composite are left alone
+func CadenceToInput(value cadence.Value, v interface{}) error {
+	return CadenceToInputWithOption(value, v, defaultOptions)
+}
+
+// / Read a cadence value into the go value v points to using the sendt in options to control how it is done
+// /  interface{} values are created with CadenceValueToInterfaceWithOption and the options
+func CadenceToInputWithOption(value cadence.Value, v interface{}, opt Options) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return fmt.Errorf("cannot read cadence into %T, it must be a non nil pointer", v)
+	}
+	return CadenceToReflectWithOption(value, target.Elem(), opt)
+}
+
+// / Read a cadence value into a go value that can be set
+func CadenceToReflect(value cadence.Value, target reflect.Value) error {
+	return CadenceToReflectWithOption(value, target, defaultOptions)
+}
+
+// / Read a cadence value into a go value that can be set using the sendt in options to control how it is done
+func CadenceToReflectWithOption(value cadence.Value, target reflect.Value, opt Options) error {
+	if !target.CanSet() {
+		return fmt.Errorf("cannot read cadence into a %s that can not be set", target.Type())
+	}
+	return cadenceToReflect(value, target, opt, nil)
+}
+
+var cadenceValueType = reflect.TypeOf((*cadence.Value)(nil)).Elem()
+
+// the tag is the cadence struct tag of the field the target is in, it also applies to the elements of pointers, arrays and maps
+func cadenceToReflect(value cadence.Value, target reflect.Value, opt Options, tag *structtag.Tag) error {
+	targetType := target.Type()
+
+	if targetType.Kind() == reflect.Pointer {
+		value = unwrapOptionalOnce(value)
+		if value == nil {
+			target.Set(reflect.Zero(targetType))
+			return nil
+		}
+		if target.IsNil() {
+			target.Set(reflect.New(targetType.Elem()))
+		}
+		return cadenceToReflect(value, target.Elem(), opt, tag)
+	}
+
+	if unmarshaler, ok := cadenceUnmarshaler(target); ok {
+		return unmarshaler.UnmarshalCadence(value)
+	}
+
+	if targetType.Kind() == reflect.Interface && targetType.Implements(cadenceValueType) {
+		if value != nil && reflect.TypeOf(value).AssignableTo(targetType) {
+			target.Set(reflect.ValueOf(value))
+			return nil
+		}
+	}
+
+	value = unwrapOptional(value)
+	if value == nil {
+		target.Set(reflect.Zero(targetType))
+		return nil
+	}
+
+	if reflect.TypeOf(value).AssignableTo(targetType) && targetType.Kind() != reflect.Interface {
+		target.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	if ok, err := cadenceToStandardType(value, target, tag); ok {
+		return err
+	}
+
+	switch targetType.Kind() {
+	case reflect.Interface:
+		if targetType.NumMethod() != 0 {
+			return decodeError(value, targetType)
+		}
+		result := CadenceValueToInterfaceWithOption(value, opt)
+		if result != nil {
+			target.Set(reflect.ValueOf(result))
+		}
+		return nil
+	case reflect.Bool:
+		b, ok := value.(cadence.Bool)
+		if !ok {
+			return decodeError(value, targetType)
+		}
+		target.SetBool(bool(b))
+		return nil
+	case reflect.String:
+		switch value := value.(type) {
+		case cadence.String:
+			target.SetString(string(value))
+		case cadence.Character:
+			target.SetString(string(value))
+		case cadence.Address:
+			target.SetString(value.String())
+		case cadence.Path:
+			target.SetString(value.String())
+		default:
+			return decodeError(value, targetType)
+		}
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := cadenceToBigInt(value, targetType)
+		if err != nil {
+			return err
+		}
+		if !i.IsInt64() || target.OverflowInt(i.Int64()) {
+			return fmt.Errorf("cannot read %s into %s, it is out of range", value, targetType)
+		}
+		target.SetInt(i.Int64())
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := cadenceToBigInt(value, targetType)
+		if err != nil {
+			return err
+		}
+		if !i.IsUint64() || target.OverflowUint(i.Uint64()) {
+			return fmt.Errorf("cannot read %s into %s, it is out of range", value, targetType)
+		}
+		target.SetUint(i.Uint64())
+		return nil
+	case reflect.Float32, reflect.Float64:
+		rat, ok := numberKey(value)
+		if !ok {
+			return decodeError(value, targetType)
+		}
+		f, _ := rat.Float64()
+		if target.OverflowFloat(f) {
+			return fmt.Errorf("cannot read %s into %s, it is out of range", value, targetType)
+		}
+		target.SetFloat(f)
+		return nil
+	case reflect.Slice:
+		array, ok := value.(cadence.Array)
+		if !ok {
+			return decodeError(value, targetType)
+		}
+		slice := reflect.MakeSlice(targetType, len(array.Values), len(array.Values))
+		for i, element := range array.Values {
+			if err := cadenceToReflect(element, slice.Index(i), opt, tag); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		target.Set(slice)
+		return nil
+	case reflect.Array:
+		array, ok := value.(cadence.Array)
+		if !ok {
+			return decodeError(value, targetType)
+		}
+		if len(array.Values) != target.Len() {
+			return fmt.Errorf("cannot read %d elements into %s", len(array.Values), targetType)
+		}
+		for i, element := range array.Values {
+			if err := cadenceToReflect(element, target.Index(i), opt, tag); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		return nil
+	case reflect.Map:
+		pairs, ok := cadenceKeyValuePairs(value)
+		if !ok {
+			return decodeError(value, targetType)
+		}
+		result := reflect.MakeMapWithSize(targetType, len(pairs))
+		for _, pair := range pairs {
+			key := reflect.New(targetType.Key()).Elem()
+			if err := cadenceToReflect(pair.Key, key, opt, nil); err != nil {
+				return fmt.Errorf("key %s: %w", pair.Key, err)
+			}
+			element := reflect.New(targetType.Elem()).Elem()
+			if err := cadenceToReflect(pair.Value, element, opt, tag); err != nil {
+				return fmt.Errorf("key %s: %w", pair.Key, err)
+			}
+			result.SetMapIndex(key, element)
+		}
+		target.Set(result)
+		return nil
+	case reflect.Struct:
+		values, fields, ok := compositeFields(value)
+		if !ok {
+			return decodeError(value, targetType)
+		}
+		plan, err := structPlanFor(targetType)
+		if err != nil {
+			return err
+		}
+
+		byName := make(map[string]cadence.Value, len(values))
+		for i, fieldValue := range values {
+			byName[compositeFieldName(fields, i)] = fieldValue
+		}
+		for _, field := range plan.fields {
+			fieldValue, ok := byName[field.name]
+			if !ok {
+				continue
+			}
+			fieldTarget, err := settableFieldByIndex(target, field)
+			if err != nil {
+				return err
+			}
+			if err := cadenceToReflect(fieldValue, fieldTarget, opt, field.tag); err != nil {
+				return fmt.Errorf("field %s: %w", field.field.Name, err)
+			}
+		}
+		return nil
+	}
+
+	return decodeError(value, targetType)
+}
+
+// read standard library types that are not read by their kind, ok is false if the target is not such a type
+func cadenceToStandardType(value cadence.Value, target reflect.Value, tag *structtag.Tag) (bool, error) {
+	targetType := target.Type()
+	switch targetType {
+	case addressType:
+		address, ok := value.(cadence.Address)
+		if !ok {
+			return true, decodeError(value, targetType)
+		}
+		target.Set(reflect.ValueOf(Address(address)))
+		return true, nil
+	case bigIntType:
+		i, err := cadenceToBigInt(value, targetType)
+		if err != nil {
+			return true, err
+		}
+		target.Set(reflect.ValueOf(*i))
+		return true, nil
+	case timeType:
+		seconds, nanos, err := fixedPointSeconds(value, targetType)
+		if err != nil {
+			return true, err
+		}
+		target.Set(reflect.ValueOf(time.Unix(seconds, nanos).UTC()))
+		return true, nil
+	case durationType:
+		seconds, nanos, err := fixedPointSeconds(value, targetType)
+		if err != nil {
+			return true, err
+		}
+		if seconds > math.MaxInt64/int64(time.Second) || seconds < math.MinInt64/int64(time.Second) {
+			return true, fmt.Errorf("cannot read %s into %s, it is out of range", value, targetType)
+		}
+		target.SetInt(seconds*int64(time.Second) + nanos)
+		return true, nil
+	case jsonNumberType:
+		if _, ok := numberKey(value); !ok {
+			return true, decodeError(value, targetType)
+		}
+		target.SetString(value.String())
+		return true, nil
+	}
+
+	if targetType.Kind() == reflect.Slice && targetType.Elem().Kind() == reflect.Uint8 && tagHasOption(tag, "String") {
+		s, ok := value.(cadence.String)
+		if !ok {
+			return true, decodeError(value, targetType)
+		}
+		bytes, err := hex.DecodeString(string(s))
+		if err != nil {
+			return true, fmt.Errorf("cannot read %s into %s: %w", value, targetType, err)
+		}
+		target.SetBytes(bytes)
+		return true, nil
+	}
+	return false, nil
+}
+
+// the integer in a cadence number, fixed point numbers must be whole
+func cadenceToBigInt(value cadence.Value, targetType reflect.Type) (*big.Int, error) {
+	rat, ok := numberKey(value)
+	if !ok {
+		return nil, decodeError(value, targetType)
+	}
+	if !rat.IsInt() {
+		return nil, fmt.Errorf("cannot read %s into %s, it is not a whole number", value, targetType)
+	}
+	return new(big.Int).Set(rat.Num()), nil
+}
+
+// the whole seconds and nanoseconds in a UFix64 or Fix64
+func fixedPointSeconds(value cadence.Value, targetType reflect.Type) (int64, int64, error) {
+	const scale = 100_000_000
+	switch value := value.(type) {
+	case cadence.UFix64:
+		if uint64(value)/scale > math.MaxInt64 {
+			return 0, 0, fmt.Errorf("cannot read %s into %s, it is out of range", value, targetType)
+		}
+		return int64(uint64(value) / scale), int64(uint64(value)%scale) * 10, nil
+	case cadence.Fix64:
+		return int64(value) / scale, int64(value) % scale * 10, nil
+	}
+	return 0, 0, decodeError(value, targetType)
+}
+
+func unwrapOptionalOnce(value cadence.Value) cadence.Value {
+	if optional, ok := value.(cadence.Optional); ok {
+		return optional.Value
+	}
+	return value
+}
+
+// the field to set, nil embedded pointers on the way are allocated
+func settableFieldByIndex(value reflect.Value, field fieldPlan) (reflect.Value, error) {
+	for i, index := range field.index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				if !value.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot read field %s, the embedded %s is nil and not exported", field.field.Name, value.Type())
+				}
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(index)
+	}
+	return value, nil
+}
+
+// the pairs of a dictionary, composites are read as a dictionary from field name to value
+func cadenceKeyValuePairs(value cadence.Value) ([]cadence.KeyValuePair, bool) {
+	if dictionary, ok := value.(cadence.Dictionary); ok {
+		return dictionary.Pairs, true
+	}
+	values, fields, ok := compositeFields(value)
+	if !ok {
+		return nil, false
+	}
+	pairs := make([]cadence.KeyValuePair, len(values))
+	for i, fieldValue := range values {
+		pairs[i] = cadence.KeyValuePair{Key: cadence.String(compositeFieldName(fields, i)), Value: fieldValue}
+	}
+	return pairs, true
+}
+
+func decodeError(value cadence.Value, targetType reflect.Type) error {
+	return fmt.Errorf("cannot read %s into %s", describeValue(value), targetType)
+}
+
+func describeValue(value cadence.Value) string {
+	if value == nil {
+		return "nil"
+	}
+	if value.Type() == nil {
+		return fmt.Sprintf("%T", value)
+	}
+	return value.Type().ID()
+}
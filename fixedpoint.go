@@ -0,0 +1,127 @@
+package underflow
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/onflow/cadence"
+)
+
+// Fix64 and UFix64 have 8 decimals
+var fixedPointScale = big.NewInt(100_000_000)
+
+// / Convert a float into a UFix64 or Fix64, the float is rounded to 8 decimals with the rounding mode
+// /  The shortest decimal representation of the float is used so 0.1 is 0.10000000 and not 0.10000000149 for a float32
+func floatToFixedPoint(f float64, bitSize int, signed bool, mode big.RoundingMode) (cadence.Value, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("cannot convert %v to a fixed point number", f)
+	}
+	return decimalToFixedPoint(strconv.FormatFloat(f, 'f', -1, bitSize), signed, mode)
+}
+
+// / Convert a decimal string into a UFix64 or Fix64, it is rounded to 8 decimals with the rounding mode
+func decimalToFixedPoint(decimal string, signed bool, mode big.RoundingMode) (cadence.Value, error) {
+	if magnitude, negative, ok := parseExactFixedPoint(decimal); ok {
+		switch {
+		case !signed && !negative:
+			return cadence.UFix64(magnitude), nil
+		case signed && !negative && magnitude <= math.MaxInt64:
+			return cadence.Fix64(int64(magnitude)), nil
+		case signed && negative && magnitude <= 1<<63:
+			return cadence.Fix64(int64(-magnitude)), nil
+		}
+	}
+
+	rat, ok := new(big.Rat).SetString(decimal)
+	if !ok {
+		return nil, fmt.Errorf("cannot convert %q to a fixed point number, it is not a decimal number", decimal)
+	}
+
+	scaled := new(big.Rat).Mul(rat, new(big.Rat).SetInt(fixedPointScale))
+	raw := roundRat(scaled, mode)
+
+	if !signed {
+		if raw.Sign() < 0 {
+			return nil, fmt.Errorf("cannot convert negative number %s to UFix64, use Fix64 instead", decimal)
+		}
+		if !raw.IsUint64() {
+			return nil, fmt.Errorf("cannot convert %s to UFix64, it is larger than 184467440737.09551615", decimal)
+		}
+		return cadence.UFix64(raw.Uint64()), nil
+	}
+
+	if !raw.IsInt64() {
+		return nil, fmt.Errorf("cannot convert %s to Fix64, it is outside of -92233720368.54775808 to 92233720368.54775807", decimal)
+	}
+	return cadence.Fix64(raw.Int64()), nil
+}
+
+// round a rational number to an integer with the given rounding mode
+func roundRat(rat *big.Rat, mode big.RoundingMode) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(rat.Num(), rat.Denom(), new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+
+	negative := rat.Sign() < 0
+	// compare twice the remainder with the denominator to see if we are below, at or above the half way point
+	half := new(big.Int).Abs(remainder)
+	half.Lsh(half, 1)
+	cmpHalf := half.Cmp(rat.Denom())
+
+	awayFromZero := false
+	switch mode {
+	case big.ToNearestEven:
+		awayFromZero = cmpHalf > 0 || (cmpHalf == 0 && quotient.Bit(0) == 1)
+	case big.ToNearestAway:
+		awayFromZero = cmpHalf >= 0
+	case big.ToZero:
+		awayFromZero = false
+	case big.AwayFromZero:
+		awayFromZero = true
+	case big.ToNegativeInf:
+		awayFromZero = negative
+	case big.ToPositiveInf:
+		awayFromZero = !negative
+	}
+
+	if !awayFromZero {
+		return quotient
+	}
+	if negative {
+		return quotient.Sub(quotient, big.NewInt(1))
+	}
+	return quotient.Add(quotient, big.NewInt(1))
+}
+
+// the raw value of a plain decimal with at most 8 decimals that fits in an uint64, it needs no rounding so it is parsed without big numbers
+func parseExactFixedPoint(decimal string) (magnitude uint64, negative bool, ok bool) {
+	if strings.HasPrefix(decimal, "-") {
+		negative = true
+		decimal = decimal[1:]
+	}
+	integer, fraction, _ := strings.Cut(decimal, ".")
+	if integer == "" || len(fraction) > 8 {
+		return 0, false, false
+	}
+
+	digits := 0
+	for _, part := range []string{integer, fraction + strings.Repeat("0", 8-len(fraction))} {
+		for i := 0; i < len(part); i++ {
+			c := part[i]
+			if c < '0' || c > '9' {
+				return 0, false, false
+			}
+			digit := uint64(c - '0')
+			if magnitude > (math.MaxUint64-digit)/10 {
+				return 0, false, false
+			}
+			magnitude = magnitude*10 + digit
+			digits++
+		}
+	}
+	return magnitude, negative && magnitude != 0, digits > 0
+}
@@ -0,0 +1,116 @@
+package underflow
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFloatInputToCadence(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "float64", value: 42.5, want: "42.50000000"},
+		{name: "eight decimals", value: 0.12345678, want: "0.12345678"},
+		{name: "round half even down", value: 0.000000005, want: "0.00000000"},
+		{name: "round half even up", value: 0.000000015, want: "0.00000002"},
+		{name: "float32", value: float32(0.1), want: "0.10000000"},
+		{name: "max", value: 184467440737.0, want: "184467440737.00000000"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := InputToCadence(test.value, nil)
+			require.NoError(t, err)
+			assert.IsType(t, cadence.UFix64(0), value)
+			assert.Equal(t, test.want, value.String())
+		})
+	}
+}
+
+func TestFloatInputRoundingMode(t *testing.T) {
+	tests := []struct {
+		mode big.RoundingMode
+		want string
+	}{
+		{mode: big.ToNearestEven, want: "-0.00000002"},
+		{mode: big.ToNearestAway, want: "-0.00000003"},
+		{mode: big.ToZero, want: "-0.00000002"},
+		{mode: big.AwayFromZero, want: "-0.00000003"},
+		{mode: big.ToNegativeInf, want: "-0.00000003"},
+		{mode: big.ToPositiveInf, want: "-0.00000002"},
+	}
+
+	type Debug_Price struct {
+		Price float64 `cadence:"price,Fix64"`
+	}
+
+	for _, test := range tests {
+		t.Run(test.mode.String(), func(t *testing.T) {
+			value, err := InputToCadenceWithOption(Debug_Price{Price: -0.000000025}, func(string) (string, error) {
+				return "A.f8d6e0586b0a20c7.Debug.Price", nil
+			}, Options{FloatRounding: test.mode})
+			require.NoError(t, err)
+
+			price := value.(cadence.Struct).Fields[0]
+			assert.IsType(t, cadence.Fix64(0), price)
+			assert.Equal(t, test.want, price.String())
+		})
+	}
+}
+
+func TestFloatInputErrors(t *testing.T) {
+	type Debug_Prices struct {
+		Prices []float64 `cadence:"prices,Fix64"`
+	}
+	resolver := func(string) (string, error) {
+		return "A.f8d6e0586b0a20c7.Debug.Prices", nil
+	}
+
+	_, err := InputToCadence(-1.5, nil)
+	assert.ErrorContains(t, err, "cannot convert negative number -1.5 to UFix64, use Fix64 instead")
+
+	_, err = InputToCadence(184467440737.1, nil)
+	assert.ErrorContains(t, err, "it is larger than 184467440737.09551615")
+
+	_, err = InputToCadence(math.NaN(), nil)
+	assert.ErrorContains(t, err, "cannot convert NaN")
+
+	_, err = InputToCadence(Debug_Prices{Prices: []float64{-1, 92233720369}}, resolver)
+	assert.ErrorContains(t, err, "cannot convert 92233720369 to Fix64")
+
+	value, err := InputToCadence(Debug_Prices{Prices: []float64{-1, 2}}, resolver)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"prices": []interface{}{-1.0, 2.0}}, CadenceValueToInterface(value))
+}
+
+func TestDecimalToFixedPointLimits(t *testing.T) {
+	tests := []struct {
+		decimal string
+		signed  bool
+		want    cadence.Value
+	}{
+		{decimal: "184467440737.09551615", want: cadence.UFix64(math.MaxUint64)},
+		{decimal: "92233720368.54775807", signed: true, want: cadence.Fix64(math.MaxInt64)},
+		{decimal: "-92233720368.54775808", signed: true, want: cadence.Fix64(math.MinInt64)},
+		{decimal: "-0", want: cadence.UFix64(0)},
+		{decimal: "0.000000015", want: cadence.UFix64(2)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.decimal, func(t *testing.T) {
+			value, err := decimalToFixedPoint(test.decimal, test.signed, big.ToNearestEven)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, value)
+		})
+	}
+
+	_, err := decimalToFixedPoint("92233720368.54775808", true, big.ToNearestEven)
+	assert.ErrorContains(t, err, "it is outside of")
+}
@@ -0,0 +1,97 @@
+package underflow
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence"
+)
+
+// Address is a flow account address, it can be used directly in structs given to InputToCadence
+type Address [cadence.AddressLength]byte
+
+// / Parse a hex string with or without 0x into an Address, short addresses like 0x1 are padded with zeros
+func ParseAddress(input string) (Address, error) {
+	trimmed := strings.TrimPrefix(input, "0x")
+	if trimmed == "" {
+		return Address{}, fmt.Errorf("invalid address %q, it is empty", input)
+	}
+	if len(trimmed) > 2*cadence.AddressLength {
+		return Address{}, fmt.Errorf("invalid address %q, it is longer than %d bytes", input, cadence.AddressLength)
+	}
+	if len(trimmed)%2 == 1 {
+		trimmed = "0" + trimmed
+	}
+
+	b, err := hex.DecodeString(trimmed)
+	if err != nil {
+		return Address{}, fmt.Errorf("invalid address %q: %w", input, err)
+	}
+
+	var address Address
+	copy(address[cadence.AddressLength-len(b):], b)
+	return address, nil
+}
+
+// / Parse a hex string into an Address and check that it is a valid address on the chain
+func ParseAddressForChain(input string, chain Chain) (Address, error) {
+	address, err := ParseAddress(input)
+	if err != nil {
+		return Address{}, err
+	}
+	if !chain.IsValid(address) {
+		return Address{}, fmt.Errorf("invalid address %s, it is not an address on %s", address, string(chain))
+	}
+	return address, nil
+}
+
+// / Check if the address is a valid address on the chain
+func (a Address) IsValid(chain Chain) bool {
+	return chain.IsValid(a)
+}
+
+// / The address as a 0x prefixed hex string
+func (a Address) String() string {
+	return "0x" + a.Hex()
+}
+
+// / The address as a hex string without 0x
+func (a Address) Hex() string {
+	return hex.EncodeToString(a[:])
+}
+
+func (a Address) Bytes() []byte {
+	return a[:]
+}
+
+// / Convert to a cadence.Address
+func (a Address) Cadence() cadence.Address {
+	return cadence.Address(a)
+}
+
+func (a Address) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *Address) UnmarshalText(text []byte) error {
+	address, err := ParseAddress(string(text))
+	if err != nil {
+		return err
+	}
+	*a = address
+	return nil
+}
+
+func (a Address) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+func (a *Address) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	return a.UnmarshalText([]byte(text))
+}
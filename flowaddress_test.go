@@ -0,0 +1,156 @@
+package underflow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   string
+	}{
+		{input: "0xf8d6e0586b0a20c7", want: "0xf8d6e0586b0a20c7"},
+		{input: "f8d6e0586b0a20c7", want: "0xf8d6e0586b0a20c7"},
+		{input: "0x1", want: "0x0000000000000001"},
+		{input: "0x", err: "it is empty"},
+		{input: "0xf8d6e0586b0a20c700", err: "longer than 8 bytes"},
+		{input: "0xf8d6e0586b0a20cx", err: "invalid byte"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			address, err := ParseAddress(test.input)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, address.String())
+		})
+	}
+}
+
+func TestAddressChainValidation(t *testing.T) {
+	tests := []struct {
+		address string
+		chain   Chain
+		valid   bool
+	}{
+		{address: "0xf8d6e0586b0a20c7", chain: Emulator, valid: true},
+		{address: "0x01cf0e2f2f715450", chain: Emulator, valid: true},
+		{address: "0x631e88ae7f1d7c20", chain: Testnet, valid: true},
+		{address: "0x1d7e57aa55817448", chain: Mainnet, valid: true},
+		{address: "0x1d7e57aa55817448", chain: Testnet, valid: false},
+		{address: "0x631e88ae7f1d7c20", chain: Mainnet, valid: false},
+		{address: "0xf8d6e0586b0a20c8", chain: Emulator, valid: false},
+		{address: "0x0000000000000000", chain: Mainnet, valid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.address+" "+string(test.chain), func(t *testing.T) {
+			address, err := ParseAddress(test.address)
+			require.NoError(t, err)
+			assert.Equal(t, test.valid, address.IsValid(test.chain))
+
+			_, err = ParseAddressForChain(test.address, test.chain)
+			assert.Equal(t, test.valid, err == nil)
+		})
+	}
+}
+
+func TestChainForNetwork(t *testing.T) {
+	chain, err := ChainForNetwork("testnet")
+	assert.NoError(t, err)
+	assert.Equal(t, Testnet, chain)
+
+	_, err = ChainForNetwork("previewnet")
+	assert.ErrorContains(t, err, "unknown network previewnet")
+}
+
+func TestAddressJson(t *testing.T) {
+	type account struct {
+		Owner Address `json:"owner"`
+	}
+
+	address, err := ParseAddress("0x1")
+	require.NoError(t, err)
+
+	result, err := json.Marshal(account{Owner: address})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"owner":"0x0000000000000001"}`, string(result))
+
+	var decoded account
+	require.NoError(t, json.Unmarshal([]byte(`{"owner":"0xf8d6e0586b0a20c7"}`), &decoded))
+	assert.Equal(t, "0xf8d6e0586b0a20c7", decoded.Owner.String())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"owner":"0xnope"}`), &decoded))
+}
+
+type Debug_Foo3 struct {
+	Bar Address
+}
+
+func TestAddressInputToCadence(t *testing.T) {
+	address, err := ParseAddress("0xf8d6e0586b0a20c7")
+	require.NoError(t, err)
+
+	val, err := InputToCadence(Debug_Foo3{Bar: address}, func(string) (string, error) {
+		return "A.f8d6e0586b0a20c7.Debug.Foo3", nil
+	})
+	assert.NoError(t, err)
+	jsonVal, err := CadenceValueToJsonString(val)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{ "bar": "0xf8d6e0586b0a20c7" }`, jsonVal)
+	assert.Equal(t, "Address", val.(cadence.Struct).StructType.Fields[0].Type.ID())
+}
+
+func TestAddressAtIndex(t *testing.T) {
+	tests := []struct {
+		chain   Chain
+		index   uint64
+		address string
+	}{
+		{chain: Emulator, index: 1, address: "0xf8d6e0586b0a20c7"},
+		{chain: Emulator, index: 5, address: "0x01cf0e2f2f715450"},
+		{chain: Emulator, index: 6, address: "0x179b6b1cb6755e31"},
+		{chain: Emulator, index: 7, address: "0xf3fcd2c1a78f5eee"},
+		{chain: Emulator, index: 8, address: "0xe03daebed8ca0615"},
+		{chain: Emulator, index: 9, address: "0x045a1763c93006ca"},
+		{chain: Testnet, index: 1, address: "0x8c5303eaa26202d6"},
+		{chain: Mainnet, index: 1, address: "0xe467b9dd11fa00df"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.address, func(t *testing.T) {
+			address, err := test.chain.AddressAtIndex(test.index)
+			assert.NoError(t, err)
+			assert.Equal(t, test.address, address.String())
+
+			index, err := test.chain.IndexOf(address)
+			assert.NoError(t, err)
+			assert.Equal(t, test.index, index)
+		})
+	}
+}
+
+func TestAddressAtIndexErrors(t *testing.T) {
+	_, err := Emulator.AddressAtIndex(0)
+	assert.ErrorContains(t, err, "invalid address index 0")
+
+	_, err = Emulator.AddressAtIndex(1 << 45)
+	assert.ErrorContains(t, err, "invalid address index")
+
+	_, err = Chain("flow-previewnet").AddressAtIndex(1)
+	assert.ErrorContains(t, err, "unknown chain flow-previewnet")
+
+	address, err := ParseAddress("0xf8d6e0586b0a20c7")
+	require.NoError(t, err)
+	_, err = Testnet.IndexOf(address)
+	assert.ErrorContains(t, err, "it is not an address on flow-testnet")
+}
@@ -0,0 +1,160 @@
+package underflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// FlowJson is the subset of a flow.json configuration that underflow needs to resolve contract and account addresses
+type FlowJson struct {
+	Contracts   map[string]FlowJsonContract              `json:"contracts"`
+	Accounts    map[string]FlowJsonAccount               `json:"accounts"`
+	Networks    map[string]json.RawMessage               `json:"networks"`
+	Deployments map[string]map[string][]FlowJsonDeployed `json:"deployments"`
+}
+
+// FlowJsonContract is a contract entry, it can either be a plain source path or an object with source and aliases
+type FlowJsonContract struct {
+	Source  string            `json:"source"`
+	Aliases map[string]string `json:"aliases"`
+}
+
+func (c *FlowJsonContract) UnmarshalJSON(data []byte) error {
+	var source string
+	if err := json.Unmarshal(data, &source); err == nil {
+		c.Source = source
+		return nil
+	}
+
+	type contract FlowJsonContract
+	var result contract
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*c = FlowJsonContract(result)
+	return nil
+}
+
+// FlowJsonAccount is an account entry, only the address is of interest to us
+type FlowJsonAccount struct {
+	Address string `json:"address"`
+}
+
+// FlowJsonDeployed is a contract in a deployment, it can either be a plain name or an object with name and args
+type FlowJsonDeployed struct {
+	Name string `json:"name"`
+}
+
+func (d *FlowJsonDeployed) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		d.Name = name
+		return nil
+	}
+
+	type deployed FlowJsonDeployed
+	var result deployed
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*d = FlowJsonDeployed(result)
+	return nil
+}
+
+// / Read and parse a flow.json file from the given path
+func ReadFlowJson(path string) (*FlowJson, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFlowJson(content)
+}
+
+// / Parse the content of a flow.json file
+func ParseFlowJson(content []byte) (*FlowJson, error) {
+	var result FlowJson
+	if err := json.Unmarshal(content, &result); err != nil {
+		return nil, fmt.Errorf("could not parse flow.json: %w", err)
+	}
+	return &result, nil
+}
+
+// / Find the address of a contract on a network, aliases take precedence over deployments
+// /  If the contract is deployed to several accounts on the network the first account by name is used
+func (f *FlowJson) ContractAddress(contract string, network string) (string, error) {
+	c, ok := f.Contracts[contract]
+	if !ok {
+		return "", fmt.Errorf("unknown contract %s, it is not in the contracts section of flow.json", contract)
+	}
+
+	if alias, ok := c.Aliases[network]; ok {
+		return normalizeFlowJsonAddress(alias), nil
+	}
+
+	deployments := f.Deployments[network]
+	accountNames := make([]string, 0, len(deployments))
+	for accountName := range deployments {
+		accountNames = append(accountNames, accountName)
+	}
+	sort.Strings(accountNames)
+
+	for _, accountName := range accountNames {
+		for _, d := range deployments[accountName] {
+			if d.Name != contract {
+				continue
+			}
+			account, ok := f.Accounts[accountName]
+			if !ok {
+				return "", fmt.Errorf("contract %s is deployed to unknown account %s on network %s", contract, accountName, network)
+			}
+			return normalizeFlowJsonAddress(account.Address), nil
+		}
+	}
+
+	return "", fmt.Errorf("contract %s has no alias or deployment on network %s", contract, network)
+}
+
+// / Resolve a go type name on the form Contract_Struct into a qualified identifier like A.f8d6e0586b0a20c7.Contract.Struct
+func (f *FlowJson) ResolveIdentifier(name string, network string) (string, error) {
+	contract, identifier, found := strings.Cut(name, "_")
+	if !found || contract == "" || identifier == "" {
+		return "", fmt.Errorf("cannot resolve %s, type names must be on the form Contract_Struct", name)
+	}
+
+	address, err := f.ContractAddress(contract, network)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("A.%s.%s.%s", address, contract, identifier), nil
+}
+
+// / Create an InputResolver for the given network
+func (f *FlowJson) Resolver(network string) InputResolver {
+	return func(name string) (string, error) {
+		return f.ResolveIdentifier(name, network)
+	}
+}
+
+// / Create an InputResolver that resolves go type names using the contracts, aliases and deployments in a flow.json file
+func NewFlowJsonResolver(path string, network string) (InputResolver, error) {
+	flowJson, err := ReadFlowJson(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := flowJson.Networks[network]; !ok {
+		return nil, fmt.Errorf("unknown network %s in %s", network, path)
+	}
+	return flowJson.Resolver(network), nil
+}
+
+// addresses in flow.json can be written with or without 0x and leading zeros, normalize them to the 16 character form used in type ids
+func normalizeFlowJsonAddress(address string) string {
+	hex := strings.ToLower(strings.TrimPrefix(address, "0x"))
+	if len(hex) < 16 {
+		hex = strings.Repeat("0", 16-len(hex)) + hex
+	}
+	return hex
+}
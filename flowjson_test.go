@@ -0,0 +1,90 @@
+package underflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlowJsonResolver(t *testing.T) {
+	resolver, err := NewFlowJsonResolver("flow.json", "emulator")
+	require.NoError(t, err)
+
+	t.Run("resolve deployed contract", func(t *testing.T) {
+		identifier, err := resolver("Debug_Foo")
+		assert.NoError(t, err)
+		assert.Equal(t, "A.f8d6e0586b0a20c7.Debug.Foo", identifier)
+	})
+
+	t.Run("unknown contract", func(t *testing.T) {
+		_, err := resolver("Missing_Foo")
+		assert.ErrorContains(t, err, "unknown contract Missing")
+	})
+
+	t.Run("not a contract type", func(t *testing.T) {
+		_, err := resolver("Foo")
+		assert.ErrorContains(t, err, "Contract_Struct")
+	})
+
+	t.Run("input to cadence", func(t *testing.T) {
+		val, err := InputToCadence(Debug_Foo{Bar: "foo"}, resolver)
+		assert.NoError(t, err)
+		assert.Equal(t, "A.f8d6e0586b0a20c7.Debug.Foo", val.Type().ID())
+	})
+}
+
+func TestFlowJsonResolverUnknownNetwork(t *testing.T) {
+	_, err := NewFlowJsonResolver("flow.json", "previewnet")
+	assert.ErrorContains(t, err, "unknown network previewnet")
+}
+
+func TestFlowJsonAliases(t *testing.T) {
+	flowJson, err := ParseFlowJson([]byte(`{
+		"contracts": {
+			"Debug": "./contracts/Debug.cdc",
+			"NonFungibleToken": {
+				"source": "./contracts/NonFungibleToken.cdc",
+				"aliases": { "testnet": "0x631e88ae7f1d7c20" }
+			}
+		},
+		"accounts": { "emulator-account": { "address": "0xf8d6e0586b0a20c7" } },
+		"deployments": {
+			"emulator": { "emulator-account": ["NonFungibleToken", { "name": "Debug", "args": [] }] }
+		}
+	}`))
+	require.NoError(t, err)
+
+	testnet, err := flowJson.ResolveIdentifier("NonFungibleToken_NFT", "testnet")
+	assert.NoError(t, err)
+	assert.Equal(t, "A.631e88ae7f1d7c20.NonFungibleToken.NFT", testnet)
+
+	emulator, err := flowJson.ResolveIdentifier("Debug_Foo", "emulator")
+	assert.NoError(t, err)
+	assert.Equal(t, "A.f8d6e0586b0a20c7.Debug.Foo", emulator)
+
+	_, err = flowJson.ResolveIdentifier("Debug_Foo", "testnet")
+	assert.ErrorContains(t, err, "contract Debug has no alias or deployment on network testnet")
+}
+
+func TestFlowJsonContractDeployedToSeveralAccounts(t *testing.T) {
+	flowJson, err := ParseFlowJson([]byte(`{
+		"contracts": { "Debug": "./contracts/Debug.cdc" },
+		"accounts": {
+			"emulator-account": { "address": "0xf8d6e0586b0a20c7" },
+			"emulator-alice": { "address": "0x01cf0e2f2f715450" },
+			"emulator-bob": { "address": "0x179b6b1cb6755e31" }
+		},
+		"deployments": {
+			"emulator": { "emulator-bob": ["Debug"], "emulator-alice": ["Debug"], "emulator-account": ["Debug"] }
+		}
+	}`))
+	require.NoError(t, err)
+
+	// the first account by name is used every time
+	for i := 0; i < 20; i++ {
+		address, err := flowJson.ContractAddress("Debug", "emulator")
+		require.NoError(t, err)
+		assert.Equal(t, "f8d6e0586b0a20c7", address)
+	}
+}
@@ -0,0 +1,129 @@
+package underflow
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence"
+)
+
+// TokenTransfer is an amount of a fungible token that moved from one account to another in a transaction
+type TokenTransfer struct {
+	// the type id of the token contract, like A.1654653399040a61.FlowToken
+	Token string
+	// nil if the tokens were minted or came from a vault without owner that was not withdrawn in the transaction
+	From *Address
+	// nil if the tokens were burned or are left in a vault without owner
+	To     *Address
+	Amount cadence.UFix64
+}
+
+// tokens withdrawn from an account that are not deposited yet
+type pendingWithdrawal struct {
+	from   Address
+	amount cadence.UFix64
+}
+
+// / Pair the TokensWithdrawn and TokensDeposited events of the fungible token contracts in a transaction into transfers
+// /  The events must be in the order they were emitted. Deposits take from the earliest withdrawals of the same token
+// /  and a deposit can be split over several withdrawals, so a withdrawal paid out to a seller and a royalty is two transfers.
+// /  Vaults without owner are in transit, their withdrawals and deposits are not transfers themselves
+func TokenTransfers(events []cadence.Event) ([]TokenTransfer, error) {
+	transfers := []TokenTransfer{}
+	tokens := []string{}
+	pending := map[string][]pendingWithdrawal{}
+
+	for _, event := range events {
+		if event.EventType == nil {
+			continue
+		}
+		typeID := event.EventType.ID()
+		separator := strings.LastIndex(typeID, ".")
+		if separator < 0 {
+			continue
+		}
+		token, name := typeID[:separator], typeID[separator+1:]
+		if name != "TokensWithdrawn" && name != "TokensDeposited" {
+			continue
+		}
+
+		amount, owner, err := tokenEventFields(event, name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := pending[token]; !ok {
+			tokens = append(tokens, token)
+			pending[token] = nil
+		}
+		if owner == nil {
+			continue
+		}
+
+		if name == "TokensWithdrawn" {
+			pending[token] = append(pending[token], pendingWithdrawal{from: *owner, amount: amount})
+			continue
+		}
+
+		for amount > 0 && len(pending[token]) > 0 {
+			withdrawal := &pending[token][0]
+			moved := amount
+			if withdrawal.amount < moved {
+				moved = withdrawal.amount
+			}
+			from := withdrawal.from
+			transfers = append(transfers, TokenTransfer{Token: token, From: &from, To: owner, Amount: moved})
+			amount -= moved
+			withdrawal.amount -= moved
+			if withdrawal.amount == 0 {
+				pending[token] = pending[token][1:]
+			}
+		}
+		if amount > 0 {
+			transfers = append(transfers, TokenTransfer{Token: token, To: owner, Amount: amount})
+		}
+	}
+
+	for _, token := range tokens {
+		for _, withdrawal := range pending[token] {
+			from := withdrawal.from
+			transfers = append(transfers, TokenTransfer{Token: token, From: &from, Amount: withdrawal.amount})
+		}
+	}
+	return transfers, nil
+}
+
+// the amount and the from or to field of a token event, the owner is nil for vaults without owner
+func tokenEventFields(event cadence.Event, name string) (cadence.UFix64, *Address, error) {
+	ownerField := "to"
+	if name == "TokensWithdrawn" {
+		ownerField = "from"
+	}
+
+	var amount cadence.Value
+	var owner cadence.Value
+	for i, value := range event.Fields {
+		switch compositeFieldName(event.EventType.Fields, i) {
+		case "amount":
+			amount = value
+		case ownerField:
+			owner = unwrapOptional(value)
+		}
+	}
+
+	if amount == nil {
+		return 0, nil, fmt.Errorf("cannot read the amount of %s, it has no amount field", event.EventType.ID())
+	}
+	ufix, ok := amount.(cadence.UFix64)
+	if !ok {
+		return 0, nil, fmt.Errorf("cannot read the amount of %s, expected UFix64, got %s", event.EventType.ID(), describeValue(amount))
+	}
+	switch owner := owner.(type) {
+	case nil:
+		return ufix, nil, nil
+	case cadence.Address:
+		address := Address(owner)
+		return ufix, &address, nil
+	default:
+		return 0, nil, fmt.Errorf("cannot read the %s field of %s, expected Address, got %s", ownerField, event.EventType.ID(), describeValue(owner))
+	}
+}
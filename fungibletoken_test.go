@@ -0,0 +1,169 @@
+package underflow
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	flowToken = "A.1654653399040a61.FlowToken"
+	fusd      = "A.3c5959b568896393.FUSD"
+)
+
+// a TokensWithdrawn or TokensDeposited event, the owner is nil if it is empty
+func testTokenEvent(t *testing.T, token string, name string, amount string, owner string) cadence.Event {
+	t.Helper()
+	ufix, err := cadence.NewUFix64(amount)
+	require.NoError(t, err)
+	ownerValue := cadence.NewOptional(nil)
+	if owner != "" {
+		address, err := ParseAddress(owner)
+		require.NoError(t, err)
+		ownerValue = cadence.NewOptional(address.Cadence())
+	}
+	ownerField := "to"
+	if name == "TokensWithdrawn" {
+		ownerField = "from"
+	}
+	return cadence.NewEvent([]cadence.Value{ufix, ownerValue}).WithType(&cadence.EventType{
+		QualifiedIdentifier: token + "." + name,
+		Fields: []cadence.Field{
+			{Identifier: "amount", Type: cadence.UFix64Type{}},
+			{Identifier: ownerField, Type: cadence.NewOptionalType(cadence.AddressType{})},
+		},
+	})
+}
+
+// transfers rendered as token from->to amount, nil owners are empty
+func transferStrings(transfers []TokenTransfer) []string {
+	result := []string{}
+	for _, transfer := range transfers {
+		from, to := "", ""
+		if transfer.From != nil {
+			from = transfer.From.String()
+		}
+		if transfer.To != nil {
+			to = transfer.To.String()
+		}
+		result = append(result, transfer.Token+" "+from+"->"+to+" "+transfer.Amount.String())
+	}
+	return result
+}
+
+func TestTokenTransfers(t *testing.T) {
+	tests := map[string]struct {
+		events func(t *testing.T) []cadence.Event
+		want   []string
+	}{
+		"transfer and fees": {
+			events: func(t *testing.T) []cadence.Event {
+				return []cadence.Event{
+					testTokenEvent(t, flowToken, "TokensWithdrawn", "10.0", "0x01"),
+					testTokenEvent(t, flowToken, "TokensDeposited", "10.0", "0x02"),
+					testTokenEvent(t, flowToken, "TokensWithdrawn", "0.001", "0x01"),
+					testTokenEvent(t, flowToken, "TokensDeposited", "0.001", "0xf919ee77447b7497"),
+				}
+			},
+			want: []string{
+				flowToken + " 0x0000000000000001->0x0000000000000002 10.00000000",
+				flowToken + " 0x0000000000000001->0xf919ee77447b7497 0.00100000",
+			},
+		},
+		"a withdrawal split over a seller and a royalty": {
+			events: func(t *testing.T) []cadence.Event {
+				return []cadence.Event{
+					testTokenEvent(t, fusd, "TokensWithdrawn", "100.0", "0x01"),
+					testTokenEvent(t, fusd, "TokensDeposited", "5.0", "0x03"),
+					testTokenEvent(t, fusd, "TokensDeposited", "95.0", "0x02"),
+				}
+			},
+			want: []string{
+				fusd + " 0x0000000000000001->0x0000000000000003 5.00000000",
+				fusd + " 0x0000000000000001->0x0000000000000002 95.00000000",
+			},
+		},
+		"vaults without owner are in transit": {
+			events: func(t *testing.T) []cadence.Event {
+				return []cadence.Event{
+					testTokenEvent(t, flowToken, "TokensWithdrawn", "3.0", "0x01"),
+					testTokenEvent(t, flowToken, "TokensDeposited", "3.0", ""),
+					testTokenEvent(t, flowToken, "TokensWithdrawn", "2.0", "0x02"),
+					testTokenEvent(t, flowToken, "TokensDeposited", "2.0", ""),
+					testTokenEvent(t, flowToken, "TokensWithdrawn", "5.0", ""),
+					testTokenEvent(t, flowToken, "TokensDeposited", "5.0", "0x03"),
+				}
+			},
+			want: []string{
+				flowToken + " 0x0000000000000001->0x0000000000000003 3.00000000",
+				flowToken + " 0x0000000000000002->0x0000000000000003 2.00000000",
+			},
+		},
+		"minted and burned": {
+			events: func(t *testing.T) []cadence.Event {
+				return []cadence.Event{
+					testTokenEvent(t, fusd, "TokensWithdrawn", "1.0", "0x01"),
+					testTokenEvent(t, flowToken, "TokensDeposited", "7.0", "0x02"),
+					testTokenEvent(t, fusd, "TokensDeposited", "0.5", "0x02"),
+				}
+			},
+			want: []string{
+				flowToken + " ->0x0000000000000002 7.00000000",
+				fusd + " 0x0000000000000001->0x0000000000000002 0.50000000",
+				fusd + " 0x0000000000000001-> 0.50000000",
+			},
+		},
+		"tokens are not paired across contracts": {
+			events: func(t *testing.T) []cadence.Event {
+				return []cadence.Event{
+					testTokenEvent(t, flowToken, "TokensWithdrawn", "1.0", "0x01"),
+					testTokenEvent(t, fusd, "TokensWithdrawn", "1.0", "0x01"),
+					testTokenEvent(t, fusd, "TokensDeposited", "1.0", "0x02"),
+					testTokenEvent(t, flowToken, "TokensDeposited", "1.0", "0x03"),
+				}
+			},
+			want: []string{
+				fusd + " 0x0000000000000001->0x0000000000000002 1.00000000",
+				flowToken + " 0x0000000000000001->0x0000000000000003 1.00000000",
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			events := append([]cadence.Event{
+				cadence.NewEvent([]cadence.Value{cadence.NewUInt64(1)}).WithType(&cadence.EventType{
+					QualifiedIdentifier: "A.1d7e57aa55817448.NonFungibleToken.Deposit",
+					Fields:              []cadence.Field{{Identifier: "id", Type: cadence.UInt64Type{}}},
+				}),
+			}, test.events(t)...)
+			transfers, err := TokenTransfers(events)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, transferStrings(transfers))
+		})
+	}
+}
+
+func TestTokenTransfersErrors(t *testing.T) {
+	_, err := TokenTransfers([]cadence.Event{
+		cadence.NewEvent([]cadence.Value{cadence.NewUInt64(1)}).WithType(&cadence.EventType{
+			QualifiedIdentifier: flowToken + ".TokensDeposited",
+			Fields:              []cadence.Field{{Identifier: "amount", Type: cadence.UInt64Type{}}},
+		}),
+	})
+	assert.ErrorContains(t, err, "cannot read the amount of A.1654653399040a61.FlowToken.TokensDeposited, expected UFix64, got UInt64")
+
+	_, err = TokenTransfers([]cadence.Event{
+		cadence.NewEvent([]cadence.Value{cadence.UFix64(1), cadence.String("0x01")}).WithType(&cadence.EventType{
+			QualifiedIdentifier: flowToken + ".TokensWithdrawn",
+			Fields:              []cadence.Field{{Identifier: "amount", Type: cadence.UFix64Type{}}, {Identifier: "from", Type: cadence.StringType{}}},
+		}),
+	})
+	assert.ErrorContains(t, err, "cannot read the from field of A.1654653399040a61.FlowToken.TokensWithdrawn, expected Address, got String")
+
+	_, err = TokenTransfers([]cadence.Event{
+		cadence.NewEvent(nil).WithType(&cadence.EventType{QualifiedIdentifier: flowToken + ".TokensWithdrawn"}),
+	})
+	assert.ErrorContains(t, err, "it has no amount field")
+}
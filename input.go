@@ -3,7 +3,6 @@ package underflow
 import (
 	"fmt"
 	"reflect"
-	"strings"
 
 	"github.com/fatih/structtag"
 	"github.com/onflow/cadence"
@@ -13,50 +12,86 @@ import (
 type InputResolver func(string) (string, error)
 
 func InputToCadence(v interface{}, resolver InputResolver) (cadence.Value, error) {
+	return InputToCadenceWithOption(v, resolver, defaultOptions)
+}
+
+// / Convert a go value into a cadence value using the sendt in options to control how it is done
+func InputToCadenceWithOption(v interface{}, resolver InputResolver, opt Options) (cadence.Value, error) {
 	f := reflect.ValueOf(v)
-	return ReflectToCadence(f, resolver)
+	return ReflectToCadenceWithOption(f, resolver, opt)
 }
 
+var addressType = reflect.TypeOf(Address{})
+
 func ReflectToCadence(value reflect.Value, resolver InputResolver) (cadence.Value, error) {
+	return ReflectToCadenceWithOption(value, resolver, defaultOptions)
+}
+
+func ReflectToCadenceWithOption(value reflect.Value, resolver InputResolver, opt Options) (cadence.Value, error) {
+	if resolver == nil {
+		resolver = opt.Resolver
+	}
+	return reflectToCadence(value, memoizeResolver(resolver), opt, nil)
+}
+
+// the tag is the cadence struct tag of the field the value is in, it also applies to the elements of pointers, arrays and maps
+func reflectToCadence(value reflect.Value, resolver InputResolver, opt Options, tag *structtag.Tag) (cadence.Value, error) {
 	inputType := value.Type()
 
+	if marshaler, ok := cadenceMarshaler(value); ok {
+		return marshaler.MarshalCadence(resolver)
+	}
+
+	if inputType.Implements(cadenceValueType) && inputType.Kind() != reflect.Interface && value.CanInterface() {
+		return value.Interface().(cadence.Value), nil
+	}
+
+	if inputType == addressType {
+		return value.Interface().(Address).Cadence(), nil
+	}
+
+	if result, ok, err := standardTypeToCadence(value, opt, tag); ok {
+		return result, err
+	}
+
 	kind := inputType.Kind()
 	switch kind {
 	case reflect.Interface:
-		return cadence.NewValue(value.Interface())
+		if value.IsNil() {
+			return cadence.NewOptional(nil), nil
+		}
+		return reflectToCadence(value.Elem(), resolver, opt, tag)
 	case reflect.Struct:
-		var val []cadence.Value
-		fields := []cadence.Field{}
-		for i := 0; i < value.NumField(); i++ {
-			fieldValue := value.Field(i)
-			cadenceVal, err := ReflectToCadence(fieldValue, resolver)
-			if err != nil {
-				return nil, err
-			}
-			cadenceType := cadenceVal.Type()
+		plan, err := structPlanFor(inputType)
+		if err != nil {
+			return nil, err
+		}
 
-			field := inputType.Field(i)
+		// the fields have their declared type, so interface fields are AnyStruct like in CadenceTypeOf
+		structType, err := cadenceTypeOf(inputType, resolver, nil)
+		if err != nil {
+			return nil, err
+		}
 
-			tags, err := structtag.Parse(string(field.Tag))
+		val := make([]cadence.Value, 0, len(plan.fields))
+		for _, fieldPlan := range plan.fields {
+			fieldValue, err := fieldByIndex(value, fieldPlan)
 			if err != nil {
 				return nil, err
 			}
+			field, tag, explicitType := fieldPlan.field, fieldPlan.tag, fieldPlan.explicitType
 
-			name := ""
-			tag, err := tags.Get("cadence")
-			if err != nil {
-				tag, _ = tags.Get("json")
-			}
-			if tag != nil {
-				name = tag.Name
-			}
-
-			if name == "-" {
-				continue
-			}
-
-			if name == "" {
-				name = strings.ToLower(field.Name)
+			var cadenceVal cadence.Value
+			if explicitType != nil {
+				cadenceVal, err = convertToCadenceType(fieldValue, explicitType, resolver, opt)
+				if err != nil {
+					return nil, fmt.Errorf("field %s: %w", field.Name, err)
+				}
+			} else {
+				cadenceVal, err = reflectToCadence(fieldValue, resolver, opt, tag)
+				if err != nil {
+					return nil, err
+				}
 			}
 
 			if IsTagCadecenAddress(tag) {
@@ -65,70 +100,52 @@ func ReflectToCadence(value reflect.Value, resolver InputResolver) (cadence.Valu
 				if err != nil {
 					return nil, err
 				}
-				cadenceAddress := cadence.BytesToAddress(adr.Bytes())
-				cadenceType = cadence.AddressType{}
-				cadenceVal = cadenceAddress
+				cadenceVal = cadence.BytesToAddress(adr.Bytes())
 			}
 
-			fields = append(fields, cadence.Field{
-				Identifier: name,
-				Type:       cadenceType,
-			})
-
 			val = append(val, cadenceVal)
 		}
 
-		resolvedIdentifier, err := resolver(inputType.Name())
-		if err != nil {
-			return nil, err
-		}
-		structType := cadence.StructType{
-			QualifiedIdentifier: resolvedIdentifier,
-			Fields:              fields,
-		}
-
-		structValue := cadence.NewStruct(val).WithType(&structType)
-		return structValue, nil
+		return cadence.NewStruct(val).WithType(structType.(*cadence.StructType)), nil
 
 	case reflect.Pointer:
 		if value.IsNil() {
 			return cadence.NewOptional(nil), nil
 		}
 
-		ptrValue, err := ReflectToCadence(value.Elem(), resolver)
+		ptrValue, err := reflectToCadence(value.Elem(), resolver, opt, tag)
 		if err != nil {
 			return nil, err
 		}
 		return cadence.NewOptional(ptrValue), nil
 
 	case reflect.Int:
-		return cadence.NewInt(value.Interface().(int)), nil
+		return cadence.NewInt(int(value.Int())), nil
 	case reflect.Int8:
-		return cadence.NewInt8(value.Interface().(int8)), nil
+		return cadence.NewInt8(int8(value.Int())), nil
 	case reflect.Int16:
-		return cadence.NewInt16(value.Interface().(int16)), nil
+		return cadence.NewInt16(int16(value.Int())), nil
 	case reflect.Int32:
-		return cadence.NewInt32(value.Interface().(int32)), nil
+		return cadence.NewInt32(int32(value.Int())), nil
 	case reflect.Int64:
-		return cadence.NewInt64(value.Interface().(int64)), nil
+		return cadence.NewInt64(value.Int()), nil
 	case reflect.Bool:
-		return cadence.NewBool(value.Interface().(bool)), nil
+		return cadence.NewBool(value.Bool()), nil
 	case reflect.Uint:
-		return cadence.NewUInt(value.Interface().(uint)), nil
+		return cadence.NewUInt(uint(value.Uint())), nil
 	case reflect.Uint8:
-		return cadence.NewUInt8(value.Interface().(uint8)), nil
+		return cadence.NewUInt8(uint8(value.Uint())), nil
 	case reflect.Uint16:
-		return cadence.NewUInt16(value.Interface().(uint16)), nil
+		return cadence.NewUInt16(uint16(value.Uint())), nil
 	case reflect.Uint32:
-		return cadence.NewUInt32(value.Interface().(uint32)), nil
+		return cadence.NewUInt32(uint32(value.Uint())), nil
 	case reflect.Uint64:
-		return cadence.NewUInt64(value.Interface().(uint64)), nil
+		return cadence.NewUInt64(value.Uint()), nil
 	case reflect.String:
-		result, err := cadence.NewString(value.Interface().(string))
-		return result, err
-	case reflect.Float64:
-		result, err := cadence.NewUFix64(fmt.Sprintf("%f", value.Interface().(float64)))
+		result, err := cadence.NewString(value.String())
 		return result, err
+	case reflect.Float32, reflect.Float64:
+		return floatToFixedPoint(value.Float(), inputType.Bits(), tagHasOption(tag, "Fix64"), opt.FloatRounding)
 
 	case reflect.Map:
 		array := []cadence.KeyValuePair{}
@@ -137,28 +154,37 @@ func ReflectToCadence(value reflect.Value, resolver InputResolver) (cadence.Valu
 		for iter.Next() {
 			key := iter.Key()
 			val := iter.Value()
-			cadenceKey, err := ReflectToCadence(key, resolver)
+			cadenceKey, err := reflectToCadence(key, resolver, opt, nil)
 			if err != nil {
 				return nil, err
 			}
-			cadenceVal, err := ReflectToCadence(val, resolver)
+			cadenceVal, err := reflectToCadence(val, resolver, opt, tag)
 			if err != nil {
 				return nil, err
 			}
 			array = append(array, cadence.KeyValuePair{Key: cadenceKey, Value: cadenceVal})
 		}
-		return cadence.NewDictionary(array), nil
+		sortKeyValuePairs(array, opt.keyLess())
+		dictionaryType, err := cadenceTypeOf(inputType, resolver, tag)
+		if err != nil {
+			return nil, err
+		}
+		return cadence.NewDictionary(array).WithType(dictionaryType.(*cadence.DictionaryType)), nil
 	case reflect.Slice, reflect.Array:
 		array := []cadence.Value{}
 		for i := 0; i < value.Len(); i++ {
 			arrValue := value.Index(i)
-			cadenceVal, err := ReflectToCadence(arrValue, resolver)
+			cadenceVal, err := reflectToCadence(arrValue, resolver, opt, tag)
 			if err != nil {
 				return nil, err
 			}
 			array = append(array, cadenceVal)
 		}
-		return cadence.NewArray(array), nil
+		arrayType, err := cadenceTypeOf(inputType, resolver, tag)
+		if err != nil {
+			return nil, err
+		}
+		return cadence.NewArray(array).WithType(arrayType.(cadence.ArrayType)), nil
 
 	}
 
@@ -166,12 +192,16 @@ func ReflectToCadence(value reflect.Value, resolver InputResolver) (cadence.Valu
 }
 
 func IsTagCadecenAddress(tag *structtag.Tag) bool {
+	return tagHasOption(tag, "cadenceAddress")
+}
+
+func tagHasOption(tag *structtag.Tag, option string) bool {
 	if tag == nil {
 		return false
 	}
 
 	for _, opt := range tag.Options {
-		if opt == "cadenceAddress" {
+		if opt == option {
 			return true
 		}
 	}
@@ -0,0 +1,271 @@
+package underflow
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/parser"
+)
+
+// / Parse a cadence literal like {"foo": [1.5, 2.0]} into a cadence value, this is the format value.String() writes
+func ParseCadenceLiteral(input string) (cadence.Value, error) {
+	return ParseCadenceLiteralWithOption(input, defaultOptions)
+}
+
+// / Parse a cadence literal into a cadence value with options
+// /  Integers are Int and decimals UFix64, or Fix64 if they are negative, use `as` to select another type like `1 as UInt8`
+// /  Hex integers like 0xf8d6e0586b0a20c7 are addresses and composites like A.f8d6e0586b0a20c7.Debug.Foo(bar: 1) are structs
+// /  FloatRounding is used for decimals with more than 8 decimals and DictionaryKeyLess to order dictionaries
+func ParseCadenceLiteralWithOption(input string, opt Options) (cadence.Value, error) {
+	expression, errs := parser.ParseExpression(nil, []byte(input), parser.Config{})
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid cadence literal %q: %w", input, errs[0])
+	}
+	value, err := literalToCadence(expression, nil, opt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cadence literal %q: %w", input, err)
+	}
+	return value, nil
+}
+
+// convert an expression into a value of the expected type, the type is inferred if expected is nil
+func literalToCadence(expression ast.Expression, expected cadence.Type, opt Options) (cadence.Value, error) {
+	if _, ok := expected.(cadence.AnyStructType); ok {
+		expected = nil
+	}
+
+	if optional, ok := expected.(*cadence.OptionalType); ok {
+		if _, ok := expression.(*ast.NilExpression); ok {
+			return cadence.NewOptional(nil), nil
+		}
+		value, err := literalToCadence(expression, optional.Type, opt)
+		if err != nil {
+			return nil, err
+		}
+		return cadence.NewOptional(value), nil
+	}
+
+	switch expression := expression.(type) {
+	case *ast.NilExpression:
+		if expected != nil {
+			return nil, fmt.Errorf("cannot use nil as %s", expected.ID())
+		}
+		return cadence.NewOptional(nil), nil
+
+	case *ast.BoolExpression:
+		return literalWithType(cadence.NewBool(expression.Value), expected)
+
+	case *ast.StringExpression:
+		switch expected.(type) {
+		case nil, cadence.StringType, cadence.CharacterType:
+		default:
+			return nil, fmt.Errorf("cannot use a string as %s", expected.ID())
+		}
+		if expected == nil {
+			expected = cadence.StringType{}
+		}
+		return convertToCadenceType(reflect.ValueOf(expression.Value), expected, nil, opt)
+
+	case *ast.IntegerExpression:
+		return integerLiteral(expression, expected, opt)
+
+	case *ast.FixedPointExpression:
+		return fixedPointLiteral(expression, expected, opt)
+
+	case *ast.PathExpression:
+		path := cadence.Path{Domain: common.PathDomainFromIdentifier(expression.Domain.Identifier), Identifier: expression.Identifier.Identifier}
+		if path.Domain == common.PathDomainUnknown {
+			return nil, fmt.Errorf("unknown path domain %s", expression.Domain.Identifier)
+		}
+		if expected == nil {
+			return path, nil
+		}
+		return parsePath(path.String(), expected)
+
+	case *ast.ArrayExpression:
+		return arrayLiteral(expression, expected, opt)
+
+	case *ast.DictionaryExpression:
+		return dictionaryLiteral(expression, expected, opt)
+
+	case *ast.CastingExpression:
+		typ, err := ParseCadenceType(expression.TypeAnnotation.Type.String())
+		if err != nil {
+			return nil, err
+		}
+		value, err := literalToCadence(expression.Expression, typ, opt)
+		if err != nil {
+			return nil, err
+		}
+		return literalWithType(value, expected)
+
+	case *ast.InvocationExpression:
+		if expected != nil {
+			return nil, fmt.Errorf("cannot use %s as %s", expression.InvokedExpression, expected.ID())
+		}
+		return compositeLiteral(expression, opt)
+	}
+
+	return nil, fmt.Errorf("%s is not a literal", expression)
+}
+
+// check that a value that already has a type has the expected type
+func literalWithType(value cadence.Value, expected cadence.Type) (cadence.Value, error) {
+	if expected != nil && value.Type().ID() != expected.ID() {
+		return nil, fmt.Errorf("cannot use %s as %s", value.Type().ID(), expected.ID())
+	}
+	return value, nil
+}
+
+func integerLiteral(expression *ast.IntegerExpression, expected cadence.Type, opt Options) (cadence.Value, error) {
+	_, isAddress := expected.(cadence.AddressType)
+	if isAddress || (expected == nil && expression.Base == 16) {
+		if expression.Value.Sign() < 0 || expression.Value.BitLen() > 64 {
+			return nil, fmt.Errorf("%s is not an address", expression.PositiveLiteral)
+		}
+		return cadence.BytesToAddress(expression.Value.Bytes()), nil
+	}
+
+	if expected == nil {
+		return cadence.NewIntFromBig(expression.Value), nil
+	}
+	if !isNumberType(expected) {
+		return nil, fmt.Errorf("cannot use an integer as %s", expected.ID())
+	}
+	return convertToCadenceType(reflect.ValueOf(expression.Value), expected, nil, opt)
+}
+
+func fixedPointLiteral(expression *ast.FixedPointExpression, expected cadence.Type, opt Options) (cadence.Value, error) {
+	fractional := expression.Fractional.String()
+	decimal := fmt.Sprintf("%s.%s%s", expression.UnsignedInteger, strings.Repeat("0", int(expression.Scale)-len(fractional)), fractional)
+	if expression.Negative {
+		decimal = "-" + decimal
+	}
+
+	switch expected.(type) {
+	case nil:
+		return decimalToFixedPoint(decimal, expression.Negative, opt.FloatRounding)
+	case cadence.UFix64Type, cadence.Fix64Type:
+		return convertToCadenceType(reflect.ValueOf(decimal), expected, nil, opt)
+	}
+	return nil, fmt.Errorf("cannot use a decimal as %s", expected.ID())
+}
+
+func isNumberType(typ cadence.Type) bool {
+	if _, ok := integerTypes[typ.ID()]; ok {
+		return true
+	}
+	switch typ.(type) {
+	case cadence.UFix64Type, cadence.Fix64Type:
+		return true
+	}
+	return false
+}
+
+func arrayLiteral(expression *ast.ArrayExpression, expected cadence.Type, opt Options) (cadence.Value, error) {
+	var elementType cadence.Type
+	switch typ := expected.(type) {
+	case nil:
+	case *cadence.VariableSizedArrayType:
+		elementType = typ.ElementType
+	case *cadence.ConstantSizedArrayType:
+		if uint(len(expression.Values)) != typ.Size {
+			return nil, fmt.Errorf("cannot use %d elements as %s", len(expression.Values), typ.ID())
+		}
+		elementType = typ.ElementType
+	default:
+		return nil, fmt.Errorf("cannot use an array as %s", expected.ID())
+	}
+
+	values := make([]cadence.Value, len(expression.Values))
+	for i, element := range expression.Values {
+		value, err := literalToCadence(element, elementType, opt)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		values[i] = value
+	}
+
+	if expected == nil {
+		expected = cadence.NewVariableSizedArrayType(commonLiteralType(values))
+	}
+	return cadence.NewArray(values).WithType(expected.(cadence.ArrayType)), nil
+}
+
+func dictionaryLiteral(expression *ast.DictionaryExpression, expected cadence.Type, opt Options) (cadence.Value, error) {
+	var keyType, elementType cadence.Type
+	if expected != nil {
+		dictionary, ok := expected.(*cadence.DictionaryType)
+		if !ok {
+			return nil, fmt.Errorf("cannot use a dictionary as %s", expected.ID())
+		}
+		keyType, elementType = dictionary.KeyType, dictionary.ElementType
+	}
+
+	pairs := make([]cadence.KeyValuePair, len(expression.Entries))
+	keys := make([]cadence.Value, len(expression.Entries))
+	values := make([]cadence.Value, len(expression.Entries))
+	for i, entry := range expression.Entries {
+		key, err := literalToCadence(entry.Key, keyType, opt)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", entry.Key, err)
+		}
+		value, err := literalToCadence(entry.Value, elementType, opt)
+		if err != nil {
+			return nil, fmt.Errorf("value of %s: %w", entry.Key, err)
+		}
+		pairs[i] = cadence.KeyValuePair{Key: key, Value: value}
+		keys[i], values[i] = key, value
+	}
+
+	if expected == nil {
+		expected = cadence.NewDictionaryType(commonLiteralType(keys), commonLiteralType(values))
+	}
+	sortKeyValuePairs(pairs, opt.keyLess())
+	return cadence.NewDictionary(pairs).WithType(expected.(*cadence.DictionaryType)), nil
+}
+
+// the type of all the values if they have the same type, else AnyStruct
+func commonLiteralType(values []cadence.Value) cadence.Type {
+	if len(values) == 0 {
+		return cadence.AnyStructType{}
+	}
+	typ := values[0].Type()
+	for _, value := range values[1:] {
+		if value.Type().ID() != typ.ID() {
+			return cadence.AnyStructType{}
+		}
+	}
+	return typ
+}
+
+func compositeLiteral(expression *ast.InvocationExpression, opt Options) (cadence.Value, error) {
+	identifier := expression.InvokedExpression.String()
+	values := make([]cadence.Value, len(expression.Arguments))
+	fields := make([]cadence.Field, len(expression.Arguments))
+	for i, argument := range expression.Arguments {
+		if argument.Label == "" {
+			return nil, fmt.Errorf("argument %d of %s must have a label", i, identifier)
+		}
+		value, err := literalToCadence(argument.Expression, nil, opt)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", argument.Label, err)
+		}
+		values[i] = value
+		fields[i] = cadence.Field{Identifier: argument.Label, Type: value.Type()}
+	}
+
+	location, qualifiedIdentifier, err := common.DecodeTypeID(nil, identifier)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the type %s: %w", identifier, err)
+	}
+	return cadence.NewStruct(values).WithType(&cadence.StructType{
+		Location:            location,
+		QualifiedIdentifier: qualifiedIdentifier,
+		Fields:              fields,
+	}), nil
+}
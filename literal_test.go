@@ -0,0 +1,103 @@
+package underflow
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCadenceLiteral(t *testing.T) {
+	tests := map[string]struct {
+		want   string
+		typeID string
+	}{
+		`true`:                       {want: `true`, typeID: "Bool"},
+		`nil`:                        {want: `nil`, typeID: "Never?"},
+		`"foo"`:                      {want: `"foo"`, typeID: "String"},
+		`42`:                         {want: `42`, typeID: "Int"},
+		`-42`:                        {want: `-42`, typeID: "Int"},
+		`1.5`:                        {want: `1.50000000`, typeID: "UFix64"},
+		`-1.0005`:                    {want: `-1.00050000`, typeID: "Fix64"},
+		`0xf8d6e0586b0a20c7`:         {want: `0xf8d6e0586b0a20c7`, typeID: "Address"},
+		`/storage/foo`:               {want: `/storage/foo`, typeID: "StoragePath"},
+		`42 as UInt8`:                {want: `42`, typeID: "UInt8"},
+		`1 as UFix64`:                {want: `1.00000000`, typeID: "UFix64"},
+		`"a" as Character`:           {want: `"a"`, typeID: "Character"},
+		`1 as UInt8?`:                {want: `1`, typeID: "UInt8?"},
+		`[1, 2]`:                     {want: `[1, 2]`, typeID: "[Int]"},
+		`[1, "foo"]`:                 {want: `[1, "foo"]`, typeID: "[AnyStruct]"},
+		`[1, 2] as [UInt8; 2]`:       {want: `[1, 2]`, typeID: "[UInt8;2]"},
+		`{"b": 2.0, "a": 1.0}`:       {want: `{"a": 1.00000000, "b": 2.00000000}`, typeID: "{String:UFix64}"},
+		`{"a": [1, nil]}`:            {want: `{"a": [1, nil]}`, typeID: "{String:[AnyStruct]}"},
+		`{"a": 1} as {String: Int8}`: {want: `{"a": 1}`, typeID: "{String:Int8}"},
+		`A.f8d6e0586b0a20c7.Debug.Foo(bar: "baz", amount: 1.0)`: {
+			want:   `A.f8d6e0586b0a20c7.Debug.Foo(bar: "baz", amount: 1.00000000)`,
+			typeID: "A.f8d6e0586b0a20c7.Debug.Foo",
+		},
+	}
+	for input, test := range tests {
+		t.Run(input, func(t *testing.T) {
+			value, err := ParseCadenceLiteral(input)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, value.String())
+			assert.Equal(t, test.typeID, value.Type().ID())
+		})
+	}
+}
+
+func TestParseCadenceLiteralRoundTrip(t *testing.T) {
+	value := cadence.NewStruct([]cadence.Value{
+		cadence.UFix64(150_000_000),
+		cadence.BytesToAddress([]byte{0x1}),
+		cadence.NewArray([]cadence.Value{cadence.String("foo")}),
+	}).WithType(&cadence.StructType{
+		QualifiedIdentifier: "A.f8d6e0586b0a20c7.Debug.Deposit",
+		Fields: []cadence.Field{
+			{Identifier: "amount", Type: cadence.UFix64Type{}},
+			{Identifier: "to", Type: cadence.AddressType{}},
+			{Identifier: "tags", Type: cadence.NewVariableSizedArrayType(cadence.StringType{})},
+		},
+	})
+
+	parsed, err := ParseCadenceLiteral(value.String())
+	require.NoError(t, err)
+	assert.Equal(t, value.String(), parsed.String())
+	assert.Equal(t, "A.f8d6e0586b0a20c7.Debug.Deposit", parsed.Type().ID())
+}
+
+func TestParseCadenceLiteralRounding(t *testing.T) {
+	value, err := ParseCadenceLiteral(`0.123456789`)
+	require.NoError(t, err)
+	assert.Equal(t, cadence.UFix64(12345679), value)
+
+	value, err = ParseCadenceLiteralWithOption(`0.123456789`, Options{FloatRounding: big.ToZero})
+	require.NoError(t, err)
+	assert.Equal(t, cadence.UFix64(12345678), value)
+}
+
+func TestParseCadenceLiteralErrors(t *testing.T) {
+	tests := map[string]string{
+		`1 2`:                             "unexpected token",
+		`256 as UInt8`:                    "it is out of range",
+		`1.5 as UInt8`:                    "cannot use a decimal as UInt8",
+		`"foo" as Int`:                    "cannot use a string as Int",
+		`true as String`:                  "cannot use Bool as String",
+		`nil as Int`:                      "cannot use nil as Int",
+		`[1] as [UInt8; 2]`:               "cannot use 1 elements as [UInt8;2]",
+		`[300] as [UInt8]`:                "element 0: cannot convert 300 to UInt8",
+		`/storage/foo as PublicPath`:      "cannot convert",
+		`1 as Foo`:                        "unsupported type Foo",
+		`foo`:                             "foo is not a literal",
+		`A.f8d6e0586b0a20c7.Debug.Foo(1)`: "must have a label",
+		`0x10000000000000000`:             "is not an address",
+	}
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseCadenceLiteral(input)
+			assert.ErrorContains(t, err, want)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package underflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+)
+
+// / An option changes the Options used by Marshal, Unmarshal, Encoder and Decoder
+type Option func(*Options)
+
+// / Resolve the names of go structs into cadence type ids with the resolver
+func WithResolver(resolver InputResolver) Option {
+	return func(opt *Options) {
+		opt.Resolver = resolver
+	}
+}
+
+// / Use all the options in opt, options after it can change them
+func WithOptions(options Options) Option {
+	return func(opt *Options) {
+		*opt = options
+	}
+}
+
+// / Round floats to the 8 decimals of UFix64 and Fix64 with the rounding mode
+func WithFloatRounding(mode big.RoundingMode) Option {
+	return func(opt *Options) {
+		opt.FloatRounding = mode
+	}
+}
+
+// / Order the keys of dictionaries created from go maps with less
+func WithDictionaryKeyLess(less func(a, b cadence.Value) bool) Option {
+	return func(opt *Options) {
+		opt.DictionaryKeyLess = less
+	}
+}
+
+// / Render addresses that are in the address book with their name
+func WithAddressBook(book AddressBook, includeHex bool) Option {
+	return func(opt *Options) {
+		opt.AddressBook = book
+		opt.AddressBookIncludeHex = includeHex
+	}
+}
+
+// / Rewrite type ids in the output
+func WithTypeIDTranslator(translate func(string) string) Option {
+	return func(opt *Options) {
+		opt.TranslateTypeID = translate
+	}
+}
+
+// / Keep empty values when converting cadence values into go values
+func WithEmptyValues() Option {
+	return func(opt *Options) {
+		opt.IncludeEmptyValues = true
+	}
+}
+
+func newOptions(opts []Option) Options {
+	opt := defaultOptions
+	for _, o := range opts {
+		o(&opt)
+	}
+	return opt
+}
+
+// / Convert a go value into a cadence value, cadence values are returned as they are
+func Marshal(v interface{}, opts ...Option) (cadence.Value, error) {
+	opt := newOptions(opts)
+	return InputToCadenceWithOption(v, opt.Resolver, opt)
+}
+
+// / Convert a go value into a value of a cadence type like UInt64 or {String: [UFix64]}, numbers are range checked
+func MarshalAs(v interface{}, cadenceType string, opts ...Option) (cadence.Value, error) {
+	target, err := ParseCadenceType(cadenceType)
+	if err != nil {
+		return nil, err
+	}
+	opt := newOptions(opts)
+	return convertToCadenceType(reflect.ValueOf(v), target, memoizeResolver(opt.Resolver), opt)
+}
+
+// / Read a cadence value into the go value v points to
+func Unmarshal(value cadence.Value, v interface{}, opts ...Option) error {
+	return CadenceToInputWithOption(value, v, newOptions(opts))
+}
+
+// / Writes go values as JSON-CDC, one value per line
+type Encoder struct {
+	w   io.Writer
+	opt Options
+}
+
+func NewEncoder(w io.Writer, opts ...Option) *Encoder {
+	return &Encoder{w: w, opt: newOptions(opts)}
+}
+
+// / Convert v into a cadence value and write it as JSON-CDC followed by a newline
+func (e *Encoder) Encode(v interface{}) error {
+	value, err := InputToCadenceWithOption(v, e.opt.Resolver, e.opt)
+	if err != nil {
+		return err
+	}
+	encoded, err := jsoncdc.Encode(value)
+	if err != nil {
+		return err
+	}
+	// JSON-CDC already ends with a newline
+	_, err = e.w.Write(encoded)
+	return err
+}
+
+// / Reads JSON-CDC values from a stream into go values
+type Decoder struct {
+	decoder *json.Decoder
+	opt     Options
+}
+
+func NewDecoder(r io.Reader, opts ...Option) *Decoder {
+	return &Decoder{decoder: json.NewDecoder(r), opt: newOptions(opts)}
+}
+
+// / Read the next JSON-CDC value into the go value v points to, use a *cadence.Value to get the value itself
+// /  It returns io.EOF when there are no more values
+func (d *Decoder) Decode(v interface{}) error {
+	var raw json.RawMessage
+	if err := d.decoder.Decode(&raw); err != nil {
+		return err
+	}
+	value, err := jsoncdc.Decode(nil, raw)
+	if err != nil {
+		return fmt.Errorf("cannot decode JSON-CDC: %w", err)
+	}
+	return CadenceToInputWithOption(value, v, d.opt)
+}
+
+// / More reports if there is another value in the stream
+func (d *Decoder) More() bool {
+	return d.decoder.More()
+}
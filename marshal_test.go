@@ -0,0 +1,97 @@
+package underflow
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalUnmarshal(t *testing.T) {
+	resolver := func(string) (string, error) {
+		return "A.f8d6e0586b0a20c7.Debug.Foo", nil
+	}
+
+	value, err := Marshal(Debug_Foo{Bar: "baz"}, WithResolver(resolver))
+	require.NoError(t, err)
+	assert.Equal(t, `A.f8d6e0586b0a20c7.Debug.Foo(bar: "baz")`, value.String())
+
+	var result Debug_Foo
+	require.NoError(t, Unmarshal(value, &result))
+	assert.Equal(t, Debug_Foo{Bar: "baz"}, result)
+
+	same, err := Marshal(value)
+	require.NoError(t, err)
+	assert.Equal(t, value, same)
+
+	_, err = Marshal(Debug_Foo{Bar: "baz"})
+	assert.ErrorContains(t, err, "cannot convert underflow.Debug_Foo to cadence without a resolver")
+
+	_, err = MarshalAs([]Debug_Foo{{Bar: "baz"}}, "[AnyStruct]")
+	assert.ErrorContains(t, err, "without a resolver")
+}
+
+func TestMarshalOptions(t *testing.T) {
+	value, err := Marshal(0.000000015, WithFloatRounding(big.ToZero))
+	require.NoError(t, err)
+	assert.Equal(t, "0.00000001", value.String())
+
+	value, err = Marshal(map[string]int{"a": 1, "b": 2}, WithDictionaryKeyLess(func(a, b cadence.Value) bool {
+		return CadenceKeyLess(b, a)
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, `{"b": 2, "a": 1}`, value.String())
+
+	value, err = Marshal(-1.5, WithOptions(Options{}), WithFloatRounding(big.ToNearestAway))
+	assert.ErrorContains(t, err, "use Fix64 instead")
+	assert.Nil(t, value)
+}
+
+func TestMarshalAs(t *testing.T) {
+	value, err := MarshalAs(42, "UInt8")
+	require.NoError(t, err)
+	assert.Equal(t, cadence.NewUInt8(42), value)
+
+	value, err = MarshalAs(map[string][]float64{"a": {1.5}}, "{String: [UFix64]}")
+	require.NoError(t, err)
+	assert.Equal(t, `{"a": [1.50000000]}`, value.String())
+	assert.Equal(t, "{String:[UFix64]}", value.Type().ID())
+
+	_, err = MarshalAs(256, "UInt8")
+	assert.ErrorContains(t, err, "it is out of range")
+	_, err = MarshalAs(1, "Foo")
+	assert.ErrorContains(t, err, "unsupported type Foo")
+}
+
+func TestEncoderDecoder(t *testing.T) {
+	var buffer bytes.Buffer
+	encoder := NewEncoder(&buffer)
+	require.NoError(t, encoder.Encode("foo"))
+	require.NoError(t, encoder.Encode([]uint64{1, 2}))
+	assert.Equal(t, strings.Join([]string{
+		`{"value":"foo","type":"String"}`,
+		`{"value":[{"value":"1","type":"UInt64"},{"value":"2","type":"UInt64"}],"type":"Array"}`,
+		"",
+	}, "\n"), buffer.String())
+
+	decoder := NewDecoder(&buffer)
+	var text string
+	require.True(t, decoder.More())
+	require.NoError(t, decoder.Decode(&text))
+	assert.Equal(t, "foo", text)
+
+	var raw cadence.Value
+	require.NoError(t, decoder.Decode(&raw))
+	assert.Equal(t, "[1, 2]", raw.String())
+
+	assert.False(t, decoder.More())
+	assert.Equal(t, io.EOF, decoder.Decode(&raw))
+
+	err := NewDecoder(strings.NewReader(`{"type":"Foo"}`)).Decode(&raw)
+	assert.ErrorContains(t, err, "cannot decode JSON-CDC")
+}
@@ -0,0 +1,57 @@
+package underflow
+
+import (
+	"reflect"
+
+	"github.com/onflow/cadence"
+)
+
+// / A type that creates its own cadence value, InputToCadence uses it instead of reflection
+type CadenceMarshaler interface {
+	MarshalCadence(resolver InputResolver) (cadence.Value, error)
+}
+
+// / A type that reads itself from a cadence value, CadenceToInput uses it instead of reflection
+type CadenceUnmarshaler interface {
+	UnmarshalCadence(value cadence.Value) error
+}
+
+var (
+	marshalerType   = reflect.TypeOf((*CadenceMarshaler)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*CadenceUnmarshaler)(nil)).Elem()
+)
+
+// the marshaler of a value, methods on the pointer are used if the value is addressable
+//
+//	pointers are never marshalers themselves since they are optionals, the value they point to is
+func cadenceMarshaler(value reflect.Value) (CadenceMarshaler, bool) {
+	if value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		return nil, false
+	}
+	if value.Type().Implements(marshalerType) && value.CanInterface() {
+		return value.Interface().(CadenceMarshaler), true
+	}
+	if value.CanAddr() && reflect.PointerTo(value.Type()).Implements(marshalerType) && value.Addr().CanInterface() {
+		return value.Addr().Interface().(CadenceMarshaler), true
+	}
+	return nil, false
+}
+
+// values of the type are converted with their marshaler, so their cadence type is not known up front
+func implementsMarshaler(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Interface {
+		return false
+	}
+	return typ.Implements(marshalerType) || reflect.PointerTo(typ).Implements(marshalerType)
+}
+
+// the unmarshaler of a value that can be set, like for marshalers pointers are not unmarshalers themselves
+func cadenceUnmarshaler(value reflect.Value) (CadenceUnmarshaler, bool) {
+	if value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface || !value.CanAddr() {
+		return nil, false
+	}
+	if reflect.PointerTo(value.Type()).Implements(unmarshalerType) {
+		return value.Addr().Interface().(CadenceUnmarshaler), true
+	}
+	return nil, false
+}
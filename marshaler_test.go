@@ -0,0 +1,181 @@
+package underflow
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Level uint8
+
+var levelEnumType = cadence.NewEnumType(nil, "A.f8d6e0586b0a20c7.Debug.Level", cadence.UInt8Type{}, []cadence.Field{{Identifier: "rawValue", Type: cadence.UInt8Type{}}}, nil)
+
+func (l Level) MarshalCadence(InputResolver) (cadence.Value, error) {
+	return cadence.NewEnum([]cadence.Value{cadence.NewUInt8(uint8(l))}).WithType(levelEnumType), nil
+}
+
+func (l *Level) UnmarshalCadence(value cadence.Value) error {
+	enum, ok := value.(cadence.Enum)
+	if !ok {
+		return fmt.Errorf("expected a level enum but got %s", value)
+	}
+	*l = Level(enum.Fields[0].(cadence.UInt8))
+	return nil
+}
+
+type Price struct {
+	Amount   float64
+	Currency string
+}
+
+func (p *Price) MarshalCadence(resolver InputResolver) (cadence.Value, error) {
+	identifier, err := resolver("Debug_Price")
+	if err != nil {
+		return nil, err
+	}
+	amount, err := floatToFixedPoint(p.Amount, 64, false, big.ToNearestEven)
+	if err != nil {
+		return nil, err
+	}
+	return cadence.NewStruct([]cadence.Value{amount, cadence.String(p.Currency)}).WithType(&cadence.StructType{
+		QualifiedIdentifier: identifier,
+		Fields: []cadence.Field{
+			{Identifier: "amount", Type: cadence.UFix64Type{}},
+			{Identifier: "currency", Type: cadence.StringType{}},
+		},
+	}), nil
+}
+
+type Debug_Item struct {
+	Level  Level   `cadence:"level"`
+	Price  Price   `cadence:"price"`
+	Maybe  *Level  `cadence:"maybe"`
+	Levels []Level `cadence:"levels"`
+}
+
+func TestCadenceMarshaler(t *testing.T) {
+	resolver := func(name string) (string, error) {
+		return "A.f8d6e0586b0a20c7." + name[len("Debug_"):], nil
+	}
+	epic := Level(3)
+
+	// the item is passed as a pointer so the pointer method on Price is used
+	value, err := InputToCadence(&Debug_Item{Level: 2, Price: Price{Amount: 1.5, Currency: "FLOW"}, Maybe: &epic, Levels: []Level{1}}, resolver)
+	require.NoError(t, err)
+
+	item := value.(cadence.Optional).Value.(cadence.Struct)
+	assert.Equal(t, []string{
+		"A.f8d6e0586b0a20c7.Debug.Level(rawValue: 2)",
+		`A.f8d6e0586b0a20c7.Price(amount: 1.50000000, currency: "FLOW")`,
+		"A.f8d6e0586b0a20c7.Debug.Level(rawValue: 3)",
+		"[A.f8d6e0586b0a20c7.Debug.Level(rawValue: 1)]",
+	}, cadenceStrings(item.Fields))
+	assert.Equal(t, "[AnyStruct]", item.Fields[3].Type().ID())
+
+	var result Debug_Item
+	require.NoError(t, CadenceToInput(item, &result))
+	assert.Equal(t, Level(2), result.Level)
+	assert.Equal(t, &epic, result.Maybe)
+	assert.Equal(t, []Level{1}, result.Levels)
+	assert.Equal(t, Price{Amount: 1.5, Currency: "FLOW"}, result.Price)
+}
+
+func TestCadenceUnmarshalerError(t *testing.T) {
+	var level Level
+	err := CadenceToInput(cadence.String("epic"), &level)
+	assert.ErrorContains(t, err, `expected a level enum but got "epic"`)
+}
+
+type Debug_Decoded struct {
+	ID       uint64            `cadence:"id"`
+	Owner    string            `cadence:"owner,cadenceAddress"`
+	Address  Address           `cadence:"address"`
+	Nickname *string           `cadence:"nickname"`
+	Tags     map[string]int    `cadence:"tags"`
+	Prices   []float64         `cadence:"prices,Fix64"`
+	Key      []byte            `cadence:"key,String"`
+	Supply   big.Int           `cadence:"supply,UInt256"`
+	Created  time.Time         `cadence:"created"`
+	Any      interface{}       `cadence:"any"`
+	Raw      cadence.Value     `cadence:"raw"`
+	Fixed    [2]uint8          `cadence:"fixed"`
+	Nested   *Debug_Base       `cadence:"nested"`
+	Extra    map[string]string `cadence:"extra"`
+	Internal string            `cadence:"-"`
+}
+
+func TestCadenceToInputRoundTrip(t *testing.T) {
+	resolver := func(name string) (string, error) {
+		return "A.f8d6e0586b0a20c7.Debug." + name[len("Debug_"):], nil
+	}
+	nickname := "bjartek"
+	input := Debug_Decoded{
+		ID:       42,
+		Owner:    "0xf8d6e0586b0a20c7",
+		Address:  Address{0x1},
+		Nickname: &nickname,
+		Tags:     map[string]int{"a": 1},
+		Prices:   []float64{-1.5, 2},
+		Key:      []byte{0xde, 0xad},
+		Supply:   *big.NewInt(1000),
+		Created:  time.Date(2024, 2, 25, 12, 0, 0, 500000000, time.UTC),
+		Any:      "any",
+		Raw:      cadence.String("raw"),
+		Fixed:    [2]uint8{1, 2},
+		Nested:   &Debug_Base{ID: 7, Owner: "nested"},
+		Extra:    map[string]string{},
+		Internal: "internal",
+	}
+
+	value, err := InputToCadence(input, resolver)
+	require.NoError(t, err)
+
+	var result Debug_Decoded
+	require.NoError(t, CadenceToInput(value, &result))
+
+	input.Internal = ""
+	assert.Equal(t, input, result)
+}
+
+func TestCadenceToInputErrors(t *testing.T) {
+	var small int8
+	assert.ErrorContains(t, CadenceToInput(cadence.NewInt(1000), &small), "cannot read 1000 into int8, it is out of range")
+
+	var whole int
+	assert.ErrorContains(t, CadenceToInput(cadence.UFix64(150_000_000), &whole), "it is not a whole number")
+	assert.NoError(t, CadenceToInput(cadence.UFix64(200_000_000), &whole))
+	assert.Equal(t, 2, whole)
+
+	var text string
+	assert.ErrorContains(t, CadenceToInput(cadence.NewInt(1), &text), "cannot read Int into string")
+
+	assert.ErrorContains(t, CadenceToInput(cadence.NewInt(1), text), "it must be a non nil pointer")
+
+	var fixed [2]int
+	assert.ErrorContains(t, CadenceToInput(cadence.NewArray([]cadence.Value{cadence.NewInt(1)}), &fixed), "cannot read 1 elements into [2]int")
+
+	var list []int
+	err := CadenceToInput(cadence.NewArray([]cadence.Value{cadence.NewInt(1), cadence.String("a")}), &list)
+	assert.ErrorContains(t, err, "element 1: cannot read String into int")
+
+	var anything interface{}
+	require.NoError(t, CadenceToInput(cadence.NewOptional(cadence.String("foo")), &anything))
+	assert.Equal(t, "foo", anything)
+
+	var fields map[string]interface{}
+	structValue := cadence.NewStruct([]cadence.Value{cadence.String("bar")}).WithType(&cadence.StructType{
+		QualifiedIdentifier: "A.f8d6e0586b0a20c7.Debug.Foo",
+		Fields:              []cadence.Field{{Identifier: "foo", Type: cadence.StringType{}}},
+	})
+	require.NoError(t, CadenceToInput(structValue, &fields))
+	assert.Equal(t, map[string]interface{}{"foo": "bar"}, fields)
+
+	target := reflect.ValueOf(Debug_Base{})
+	assert.ErrorContains(t, CadenceToReflect(structValue, target), "can not be set")
+}
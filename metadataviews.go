@@ -0,0 +1,233 @@
+package underflow
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/onflow/cadence"
+)
+
+// MetadataDisplay is the MetadataViews.Display view, the thumbnail is the url of the file
+type MetadataDisplay struct {
+	Name        string      `cadence:"name"`
+	Description string      `cadence:"description"`
+	Thumbnail   MetadataURL `cadence:"thumbnail"`
+}
+
+// MetadataRoyalties is the MetadataViews.Royalties view
+type MetadataRoyalties struct {
+	Royalties []MetadataRoyalty `cadence:"cutInfos"`
+}
+
+// MetadataRoyalty is a MetadataViews.Royalty, the cut is a fraction like 0.05 for 5%
+type MetadataRoyalty struct {
+	Receiver    MetadataReceiver `cadence:"receiver"`
+	Cut         float64          `cadence:"cut"`
+	Description string           `cadence:"description"`
+}
+
+// MetadataReceiver is the capability royalties are paid to, the path is empty for capabilities with an id
+type MetadataReceiver struct {
+	Address Address
+	Path    string
+}
+
+// MetadataEditions is the MetadataViews.Editions view
+type MetadataEditions struct {
+	Editions []MetadataEdition `cadence:"infoList"`
+}
+
+// MetadataEdition is a MetadataViews.Edition, name and max are nil if they are not set
+type MetadataEdition struct {
+	Name   *string `cadence:"name"`
+	Number uint64  `cadence:"number"`
+	Max    *uint64 `cadence:"max"`
+}
+
+// MetadataTraits is the MetadataViews.Traits view
+type MetadataTraits struct {
+	Traits []MetadataTrait `cadence:"traits"`
+}
+
+// MetadataTrait is a MetadataViews.Trait, the value is converted like CadenceValueToInterface
+type MetadataTrait struct {
+	Name        string          `cadence:"name"`
+	Value       interface{}     `cadence:"value"`
+	DisplayType *string         `cadence:"displayType"`
+	Rarity      *MetadataRarity `cadence:"rarity"`
+}
+
+// MetadataRarity is a MetadataViews.Rarity
+type MetadataRarity struct {
+	Score       *float64 `cadence:"score"`
+	Max         *float64 `cadence:"max"`
+	Description *string  `cadence:"description"`
+}
+
+// MetadataExternalURL is the MetadataViews.ExternalURL view
+type MetadataExternalURL struct {
+	URL string `cadence:"url"`
+}
+
+// MetadataNFTCollectionDisplay is the MetadataViews.NFTCollectionDisplay view, the socials are urls by name
+type MetadataNFTCollectionDisplay struct {
+	Name        string                 `cadence:"name"`
+	Description string                 `cadence:"description"`
+	ExternalURL MetadataURL            `cadence:"externalURL"`
+	SquareImage MetadataMedia          `cadence:"squareImage"`
+	BannerImage MetadataMedia          `cadence:"bannerImage"`
+	Socials     map[string]MetadataURL `cadence:"socials"`
+}
+
+// MetadataMedia is a MetadataViews.Media, the url is the url of the file
+type MetadataMedia struct {
+	URL       MetadataURL `cadence:"file"`
+	MediaType string      `cadence:"mediaType"`
+}
+
+// MetadataURL is an url read from a MetadataViews.HTTPFile, IPFSFile, ExternalURL or Media, or from a String
+type MetadataURL string
+
+// / Read the url with MetadataURLOf
+func (u *MetadataURL) UnmarshalCadence(value cadence.Value) error {
+	url, err := MetadataURLOf(value)
+	if err != nil {
+		return err
+	}
+	*u = MetadataURL(url)
+	return nil
+}
+
+// / Read the address and path of a capability
+func (r *MetadataReceiver) UnmarshalCadence(value cadence.Value) error {
+	switch value := unwrapOptional(value).(type) {
+	case cadence.PathCapability:
+		*r = MetadataReceiver{Address: Address(value.Address), Path: value.Path.String()}
+	case cadence.IDCapability:
+		*r = MetadataReceiver{Address: Address(value.Address)}
+	default:
+		return decodeError(value, reflect.TypeOf(r).Elem())
+	}
+	return nil
+}
+
+// the models of the views by their name in the MetadataViews contract
+var metadataViewTypes = map[string]reflect.Type{
+	"Display":              reflect.TypeOf(MetadataDisplay{}),
+	"Royalties":            reflect.TypeOf(MetadataRoyalties{}),
+	"Editions":             reflect.TypeOf(MetadataEditions{}),
+	"Traits":               reflect.TypeOf(MetadataTraits{}),
+	"ExternalURL":          reflect.TypeOf(MetadataExternalURL{}),
+	"NFTCollectionDisplay": reflect.TypeOf(MetadataNFTCollectionDisplay{}),
+}
+
+// the name of a type in the MetadataViews contract, ok is false for types in other contracts
+//
+//	the address is not checked so A.1d7e57aa55817448.MetadataViews.Display and MetadataViews.Display are both Display
+func metadataViewName(value cadence.Value) (string, bool) {
+	typeID, ok := compositeTypeID(value)
+	if !ok {
+		return "", false
+	}
+	parts := strings.Split(typeID, ".")
+	if len(parts) < 2 || parts[len(parts)-2] != "MetadataViews" {
+		return "", false
+	}
+	return parts[len(parts)-1], true
+}
+
+// / The url of a file, HTTPFile is its url and IPFSFile is ipfs://cid/path like its uri() function
+// /  ExternalURL and Media are the url of their file and other files are read from a url field, nil is an error
+func MetadataURLOf(value cadence.Value) (string, error) {
+	value = unwrapOptional(value)
+	if value == nil {
+		return "", fmt.Errorf("cannot read an url from nil")
+	}
+	if s, ok := value.(cadence.String); ok {
+		return string(s), nil
+	}
+
+	values, fields, ok := compositeFields(value)
+	if !ok {
+		return "", fmt.Errorf("cannot read an url from %s", describeValue(value))
+	}
+	byName := map[string]cadence.Value{}
+	for i, fieldValue := range values {
+		byName[compositeFieldName(fields, i)] = unwrapOptional(fieldValue)
+	}
+
+	name, _ := metadataViewName(value)
+	switch name {
+	case "IPFSFile":
+		cid, ok := byName["cid"].(cadence.String)
+		if !ok {
+			return "", fmt.Errorf("cannot read the cid of %s", describeValue(value))
+		}
+		url := "ipfs://" + string(cid)
+		if path, ok := byName["path"].(cadence.String); ok && path != "" {
+			url += "/" + strings.TrimPrefix(string(path), "/")
+		}
+		return url, nil
+	case "Media":
+		file, ok := byName["file"]
+		if !ok {
+			return "", fmt.Errorf("cannot read an url from %s, it has no file field", describeValue(value))
+		}
+		return MetadataURLOf(file)
+	}
+	if url, ok := byName["url"].(cadence.String); ok {
+		return string(url), nil
+	}
+	return "", fmt.Errorf("cannot read an url from %s, it has no url field", describeValue(value))
+}
+
+// / Decode a MetadataViews view into its model, like MetadataDisplay for a Display, ok is false if the value is not a view
+func DecodeMetadataView(value cadence.Value) (view interface{}, ok bool, err error) {
+	value = unwrapOptional(value)
+	name, _ := metadataViewName(value)
+	viewType, ok := metadataViewTypes[name]
+	if !ok {
+		return nil, false, nil
+	}
+
+	target := reflect.New(viewType)
+	if err := Unmarshal(value, target.Interface()); err != nil {
+		return nil, true, fmt.Errorf("cannot decode MetadataViews.%s: %w", name, err)
+	}
+	return target.Elem().Interface(), true, nil
+}
+
+// MetadataViews are the views found in a value, a view is nil if it was not found
+type MetadataViews struct {
+	Display              *MetadataDisplay
+	Royalties            *MetadataRoyalties
+	Editions             *MetadataEditions
+	Traits               *MetadataTraits
+	ExternalURL          *MetadataExternalURL
+	NFTCollectionDisplay *MetadataNFTCollectionDisplay
+}
+
+// / Find the views anywhere in a value, like the result of a script returning a struct, an array or a dictionary of views
+// /  The first view of each kind is used and views inside other views are not collected
+func DecodeMetadataViews(value cadence.Value) (MetadataViews, error) {
+	var views MetadataViews
+	found := reflect.ValueOf(&views).Elem()
+	err := Walk(value, VisitorFuncs{EnterFunc: func(path ValuePath, value cadence.Value) error {
+		view, ok, err := DecodeMetadataView(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if !ok {
+			return nil
+		}
+		name, _ := metadataViewName(value)
+		if field := found.FieldByName(name); field.IsNil() {
+			pointer := reflect.New(field.Type().Elem())
+			pointer.Elem().Set(reflect.ValueOf(view))
+			field.Set(pointer)
+		}
+		return SkipValue
+	}})
+	return views, err
+}
@@ -0,0 +1,172 @@
+package underflow
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMetadataView(t *testing.T, literal string) cadence.Value {
+	t.Helper()
+	value, err := ParseCadenceLiteral(literal)
+	require.NoError(t, err)
+	return value
+}
+
+func testRoyalties(t *testing.T) cadence.Value {
+	royalty := testMetadataView(t, `A.f8d6e0586b0a20c7.MetadataViews.Royalty(receiver: nil, cut: 0.05, description: "artist")`).(cadence.Struct)
+	royalty.Fields[0] = cadence.NewPathCapability(
+		cadence.BytesToAddress([]byte{0xf8, 0xd6, 0xe0, 0x58, 0x6b, 0x0a, 0x20, 0xc7}),
+		cadence.Path{Domain: common.PathDomainPublic, Identifier: "flowTokenReceiver"},
+		nil,
+	)
+	return cadence.NewStruct([]cadence.Value{cadence.NewArray([]cadence.Value{royalty})}).WithType(&cadence.StructType{
+		QualifiedIdentifier: "A.f8d6e0586b0a20c7.MetadataViews.Royalties",
+		Fields:              []cadence.Field{{Identifier: "cutInfos", Type: cadence.NewVariableSizedArrayType(royalty.Type())}},
+	})
+}
+
+func TestDecodeMetadataView(t *testing.T) {
+	address, err := ParseAddress("0xf8d6e0586b0a20c7")
+	require.NoError(t, err)
+	name := "First edition"
+	max := uint64(100)
+	displayType := "Number"
+	score := 9.5
+
+	tests := map[string]struct {
+		value cadence.Value
+		want  interface{}
+	}{
+		"display with http file": {
+			value: testMetadataView(t, `A.f8d6e0586b0a20c7.MetadataViews.Display(name: "Flovatar", description: "a flovatar", thumbnail: A.f8d6e0586b0a20c7.MetadataViews.HTTPFile(url: "https://flovatar.com/1.png"))`),
+			want:  MetadataDisplay{Name: "Flovatar", Description: "a flovatar", Thumbnail: "https://flovatar.com/1.png"},
+		},
+		"display with ipfs file on another address": {
+			value: testMetadataView(t, `A.ee82856bf20e2aa6.MetadataViews.Display(name: "Flovatar", description: "", thumbnail: A.ee82856bf20e2aa6.MetadataViews.IPFSFile(cid: "bafy", path: "1.png"))`),
+			want:  MetadataDisplay{Name: "Flovatar", Thumbnail: "ipfs://bafy/1.png"},
+		},
+		"royalties": {
+			value: testRoyalties(t),
+			want: MetadataRoyalties{Royalties: []MetadataRoyalty{{
+				Receiver:    MetadataReceiver{Address: address, Path: "/public/flowTokenReceiver"},
+				Cut:         0.05,
+				Description: "artist",
+			}}},
+		},
+		"editions": {
+			value: testMetadataView(t, `A.f8d6e0586b0a20c7.MetadataViews.Editions(infoList: [A.f8d6e0586b0a20c7.MetadataViews.Edition(name: "First edition", number: 1, max: 100), A.f8d6e0586b0a20c7.MetadataViews.Edition(name: nil, number: 2, max: nil)])`),
+			want:  MetadataEditions{Editions: []MetadataEdition{{Name: &name, Number: 1, Max: &max}, {Number: 2}}},
+		},
+		"traits": {
+			value: testMetadataView(t, `A.f8d6e0586b0a20c7.MetadataViews.Traits(traits: [A.f8d6e0586b0a20c7.MetadataViews.Trait(name: "eyes", value: "blue", displayType: nil, rarity: nil), A.f8d6e0586b0a20c7.MetadataViews.Trait(name: "level", value: 3, displayType: "Number", rarity: A.f8d6e0586b0a20c7.MetadataViews.Rarity(score: 9.5, max: nil, description: nil))])`),
+			want: MetadataTraits{Traits: []MetadataTrait{
+				{Name: "eyes", Value: "blue"},
+				{Name: "level", Value: 3, DisplayType: &displayType, Rarity: &MetadataRarity{Score: &score}},
+			}},
+		},
+		"external url": {
+			value: testMetadataView(t, `MetadataViews.ExternalURL(url: "https://flovatar.com")`),
+			want:  MetadataExternalURL{URL: "https://flovatar.com"},
+		},
+		"collection display": {
+			value: testMetadataView(t, `A.f8d6e0586b0a20c7.MetadataViews.NFTCollectionDisplay(
+				name: "Flovatar",
+				description: "",
+				externalURL: A.f8d6e0586b0a20c7.MetadataViews.ExternalURL(url: "https://flovatar.com"),
+				squareImage: A.f8d6e0586b0a20c7.MetadataViews.Media(file: A.f8d6e0586b0a20c7.MetadataViews.IPFSFile(cid: "square", path: nil), mediaType: "image/png"),
+				bannerImage: A.f8d6e0586b0a20c7.MetadataViews.Media(file: A.f8d6e0586b0a20c7.MetadataViews.HTTPFile(url: "https://flovatar.com/banner.png"), mediaType: "image/png"),
+				socials: {"twitter": A.f8d6e0586b0a20c7.MetadataViews.ExternalURL(url: "https://twitter.com/flovatar")}
+			)`),
+			want: MetadataNFTCollectionDisplay{
+				Name:        "Flovatar",
+				ExternalURL: "https://flovatar.com",
+				SquareImage: MetadataMedia{URL: "ipfs://square", MediaType: "image/png"},
+				BannerImage: MetadataMedia{URL: "https://flovatar.com/banner.png", MediaType: "image/png"},
+				Socials:     map[string]MetadataURL{"twitter": "https://twitter.com/flovatar"},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			view, ok, err := DecodeMetadataView(test.value)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, test.want, view)
+		})
+	}
+
+	_, ok, err := DecodeMetadataView(testMetadataView(t, `A.f8d6e0586b0a20c7.Debug.Display(name: "foo")`))
+	assert.NoError(t, err)
+	assert.False(t, ok, "only views in MetadataViews are decoded")
+
+	_, ok, err = DecodeMetadataView(testMetadataView(t, `A.f8d6e0586b0a20c7.MetadataViews.Display(name: "foo", description: "", thumbnail: A.f8d6e0586b0a20c7.Debug.File(path: "foo"))`))
+	assert.True(t, ok)
+	assert.ErrorContains(t, err, "cannot decode MetadataViews.Display: field Thumbnail: cannot read an url from A.f8d6e0586b0a20c7.Debug.File, it has no url field")
+}
+
+func TestDecodeMetadataViews(t *testing.T) {
+	value := cadence.NewArray([]cadence.Value{
+		testMetadataView(t, `A.f8d6e0586b0a20c7.Debug.NFT(id: 1, views: {
+			"display": A.f8d6e0586b0a20c7.MetadataViews.Display(name: "first", description: "", thumbnail: A.f8d6e0586b0a20c7.MetadataViews.HTTPFile(url: "https://a")),
+			"collection": A.f8d6e0586b0a20c7.MetadataViews.NFTCollectionDisplay(
+				name: "Flovatar",
+				description: "",
+				externalURL: A.f8d6e0586b0a20c7.MetadataViews.ExternalURL(url: "https://flovatar.com"),
+				squareImage: A.f8d6e0586b0a20c7.MetadataViews.Media(file: A.f8d6e0586b0a20c7.MetadataViews.HTTPFile(url: "https://a"), mediaType: "image/png"),
+				bannerImage: A.f8d6e0586b0a20c7.MetadataViews.Media(file: A.f8d6e0586b0a20c7.MetadataViews.HTTPFile(url: "https://b"), mediaType: "image/png"),
+				socials: {}
+			)
+		})`),
+		testMetadataView(t, `A.f8d6e0586b0a20c7.MetadataViews.Display(name: "second", description: "", thumbnail: A.f8d6e0586b0a20c7.MetadataViews.HTTPFile(url: "https://b"))`),
+		testRoyalties(t),
+	})
+
+	views, err := DecodeMetadataViews(value)
+	require.NoError(t, err)
+	require.NotNil(t, views.Display)
+	assert.Equal(t, "first", views.Display.Name)
+	require.NotNil(t, views.NFTCollectionDisplay)
+	assert.Equal(t, MetadataURL("https://flovatar.com"), views.NFTCollectionDisplay.ExternalURL)
+	assert.Nil(t, views.ExternalURL, "the external url inside the collection display is not collected")
+	require.NotNil(t, views.Royalties)
+	assert.Len(t, views.Royalties.Royalties, 1)
+	assert.Nil(t, views.Editions)
+	assert.Nil(t, views.Traits)
+
+	_, err = DecodeMetadataViews(cadence.NewArray([]cadence.Value{
+		testMetadataView(t, `A.f8d6e0586b0a20c7.MetadataViews.Editions(infoList: [A.f8d6e0586b0a20c7.MetadataViews.Edition(name: nil, number: -1, max: nil)])`),
+	}))
+	assert.ErrorContains(t, err, "[0]: cannot decode MetadataViews.Editions")
+}
+
+func TestMetadataURLOf(t *testing.T) {
+	url, err := MetadataURLOf(testMetadataView(t, `A.f8d6e0586b0a20c7.MetadataViews.IPFSFile(cid: "bafy", path: "/images/1.png")`))
+	require.NoError(t, err)
+	assert.Equal(t, "ipfs://bafy/images/1.png", url)
+
+	url, err = MetadataURLOf(cadence.NewOptional(cadence.String("https://a")))
+	require.NoError(t, err)
+	assert.Equal(t, "https://a", url)
+
+	_, err = MetadataURLOf(cadence.NewUInt8(1))
+	assert.ErrorContains(t, err, "cannot read an url from")
+
+	_, err = MetadataURLOf(nil)
+	assert.ErrorContains(t, err, "cannot read an url from nil")
+
+	_, err = MetadataURLOf(cadence.NewStruct([]cadence.Value{cadence.String("foo")}))
+	assert.ErrorContains(t, err, "cannot read an url from cadence.Struct, it has no url field")
+}
+
+func TestDecodeMetadataViewWithoutURL(t *testing.T) {
+	_, ok, err := DecodeMetadataView(testMetadataView(t, `A.f8d6e0586b0a20c7.MetadataViews.Display(name: "foo", description: "", thumbnail: nil)`))
+	assert.True(t, ok)
+	assert.ErrorContains(t, err, "field Thumbnail: cannot read an url from nil")
+
+	_, err = MetadataURLOf(testMetadataView(t, `A.f8d6e0586b0a20c7.MetadataViews.Media(mediaType: "image/png")`))
+	assert.ErrorContains(t, err, "cannot read an url from A.f8d6e0586b0a20c7.MetadataViews.Media, it has no file field")
+}
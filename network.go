@@ -0,0 +1,379 @@
+package underflow
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+)
+
+// NetworkMapping maps contract names to the address of the contract on every network
+//
+//	addresses are hex strings, like NetworkMapping{"Debug": {"emulator": "f8d6e0586b0a20c7"}}, a 0x prefix and upper case are also accepted
+type NetworkMapping map[string]map[string]string
+
+// / Create a network mapping from the contracts, aliases and deployments in a flow.json file
+func NewNetworkMappingFromFlowJson(path string) (NetworkMapping, error) {
+	flowJson, err := ReadFlowJson(path)
+	if err != nil {
+		return nil, err
+	}
+	return flowJson.NetworkMapping(), nil
+}
+
+// / Create a network mapping with the address of every contract on every network it is aliased or deployed to
+func (f *FlowJson) NetworkMapping() NetworkMapping {
+	networks := map[string]bool{}
+	for network := range f.Networks {
+		networks[network] = true
+	}
+	for network := range f.Deployments {
+		networks[network] = true
+	}
+	for _, contract := range f.Contracts {
+		for network := range contract.Aliases {
+			networks[network] = true
+		}
+	}
+
+	mapping := NetworkMapping{}
+	for contract := range f.Contracts {
+		addresses := map[string]string{}
+		for network := range networks {
+			address, err := f.ContractAddress(contract, network)
+			if err == nil {
+				addresses[network] = address
+			}
+		}
+		mapping[contract] = addresses
+	}
+	return mapping
+}
+
+// / Translate the address of a contract account on one network to the address of the same contract on another network
+// /  ok is false if no contract in the mapping is at the address on the from network
+func (m NetworkMapping) TranslateAddress(address cadence.Address, from string, to string) (result cadence.Address, ok bool) {
+	// iterate in a stable order so that an account with several contracts always translates the same way
+	contracts := make([]string, 0, len(m))
+	for contract := range m {
+		contracts = append(contracts, contract)
+	}
+	sort.Strings(contracts)
+
+	for _, contract := range contracts {
+		if !sameHex(m[contract][from], address.Hex()) {
+			continue
+		}
+		translated, ok := m.contractAddress(contract, to)
+		if ok {
+			return translated, true
+		}
+	}
+	return address, false
+}
+
+var typeIDLocation = regexp.MustCompile(`A\.([0-9a-fA-F]{16})\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+// / Translate all contract locations in a type id like A.f8d6e0586b0a20c7.Debug.Foo from one network to another
+// /  nested type ids like {String: A.f8d6e0586b0a20c7.Debug.Foo} are supported, unknown contracts are kept as they are
+func (m NetworkMapping) TranslateTypeID(typeID string, from string, to string) string {
+	return typeIDLocation.ReplaceAllStringFunc(typeID, func(location string) string {
+		parts := typeIDLocation.FindStringSubmatch(location)
+		address, contract := parts[1], parts[2]
+		if !sameHex(m[contract][from], address) {
+			return location
+		}
+		translated, ok := m[contract][to]
+		if !ok {
+			return location
+		}
+		return fmt.Sprintf("A.%s.%s", normalizeHex(translated), contract)
+	})
+}
+
+// / Create a function that translates type ids from one network to another, use it as Options.TranslateTypeID
+func (m NetworkMapping) TypeIDTranslator(from string, to string) func(string) string {
+	return func(typeID string) string {
+		return m.TranslateTypeID(typeID, from, to)
+	}
+}
+
+// / Wrap an InputResolver so that the identifiers it resolves for the from network are translated to the to network
+func (m NetworkMapping) TranslateResolver(resolver InputResolver, from string, to string) InputResolver {
+	return func(name string) (string, error) {
+		identifier, err := resolver(name)
+		if err != nil {
+			return "", err
+		}
+		return m.TranslateTypeID(identifier, from, to), nil
+	}
+}
+
+// / Create an InputResolver that resolves go type names on the form Contract_Struct into type ids on the network
+// /  The name is split at the first underscore, use TypeResolver for contracts with an underscore in their name
+func (m NetworkMapping) Resolver(network string) InputResolver {
+	return func(name string) (string, error) {
+		contract, identifier, found := strings.Cut(name, "_")
+		if !found || contract == "" || identifier == "" {
+			return "", fmt.Errorf("cannot resolve %s, type names must be on the form Contract_Struct", name)
+		}
+		return m.typeID(contract, identifier, network)
+	}
+}
+
+// / Create an InputResolver that looks up go type names in types, which has qualified identifiers like Market.Listing
+// /  by go name, and resolves them into type ids on the network
+func (m NetworkMapping) TypeResolver(network string, types map[string]string) InputResolver {
+	return func(name string) (string, error) {
+		qualified, ok := types[name]
+		if !ok {
+			return "", fmt.Errorf("cannot resolve %s, it is not a known type", name)
+		}
+		contract, identifier, found := strings.Cut(qualified, ".")
+		if !found || contract == "" || identifier == "" {
+			return "", fmt.Errorf("cannot resolve %s, %s is not on the form Contract.Struct", name, qualified)
+		}
+		return m.typeID(contract, identifier, network)
+	}
+}
+
+func (m NetworkMapping) typeID(contract string, identifier string, network string) (string, error) {
+	address, ok := m[contract][network]
+	if !ok {
+		return "", fmt.Errorf("contract %s has no address on network %s", contract, network)
+	}
+	return fmt.Sprintf("A.%s.%s.%s", normalizeHex(address), contract, identifier), nil
+}
+
+// the hex of an address in a mapping without 0x prefix and in lower case
+func normalizeHex(hex string) string {
+	hex = strings.TrimPrefix(strings.TrimPrefix(hex, "0x"), "0X")
+	return strings.ToLower(hex)
+}
+
+// if the hex of an address in a mapping is the hex of an address, an empty hex is not an address
+func sameHex(mapped string, hex string) bool {
+	return mapped != "" && normalizeHex(mapped) == normalizeHex(hex)
+}
+
+// imports of a file or a contract name, like import Debug from "./Debug.cdc" and import "Debug"
+var importDeclaration = regexp.MustCompile(`(?m)^(\s*)import\s+(?:(\w+)\s+from\s+"[^"]*"|"(\w+)")`)
+
+// / Replace the imports of files and contract names in cadence code with the address of the contract on the network
+// /  Imports of addresses are kept, it is an error if a contract has no address on the network
+func (m NetworkMapping) ReplaceImports(code string, network string) (string, error) {
+	var err error
+	replaced := importDeclaration.ReplaceAllStringFunc(code, func(declaration string) string {
+		parts := importDeclaration.FindStringSubmatch(declaration)
+		contract := parts[2] + parts[3]
+		address, ok := m[contract][network]
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("cannot import %s, the contract has no address on network %s", contract, network)
+			}
+			return declaration
+		}
+		return fmt.Sprintf("%simport %s from 0x%s", parts[1], contract, normalizeHex(address))
+	})
+	if err != nil {
+		return "", err
+	}
+	return replaced, nil
+}
+
+// / Translate the contract locations of all types in a cadence value from one network to another
+// /  Address values are kept as they are, use TranslateValueAndAddresses to also translate them. Values without type are kept as they are
+func (m NetworkMapping) TranslateValue(value cadence.Value, from string, to string) (cadence.Value, error) {
+	return m.translateValue(value, from, to, false)
+}
+
+// / Translate the types in a cadence value like TranslateValue and every Address value that is a contract account on the from network
+// /  The addresses are not only the ones of contracts, user data like the owner of a listing is also changed if it happens to be a contract account
+func (m NetworkMapping) TranslateValueAndAddresses(value cadence.Value, from string, to string) (cadence.Value, error) {
+	return m.translateValue(value, from, to, true)
+}
+
+func (m NetworkMapping) translateValue(value cadence.Value, from string, to string, addresses bool) (cadence.Value, error) {
+	translator := &typeTranslator{mapping: m, from: from, to: to, translated: map[cadence.Type]cadence.Type{}}
+	return Transform(value, func(path ValuePath, value cadence.Value) (cadence.Value, error) {
+		switch value := value.(type) {
+		case cadence.Address:
+			if !addresses {
+				return value, nil
+			}
+			translated, _ := m.TranslateAddress(value, from, to)
+			return translated, nil
+		case cadence.TypeValue:
+			value.StaticType = translator.translate(value.StaticType)
+			return value, nil
+		case cadence.PathCapability:
+			value.BorrowType = translator.translate(value.BorrowType)
+			return value, nil
+		case cadence.IDCapability:
+			value.BorrowType = translator.translate(value.BorrowType)
+			return value, nil
+		case cadence.Array:
+			if value.ArrayType != nil {
+				value.ArrayType = translator.translate(value.ArrayType).(cadence.ArrayType)
+			}
+			return value, nil
+		case cadence.Dictionary:
+			if value.DictionaryType != nil {
+				value.DictionaryType = translator.translate(value.DictionaryType).(*cadence.DictionaryType)
+			}
+			return value, nil
+		case cadence.Struct:
+			value.StructType = translator.translate(value.StructType).(*cadence.StructType)
+			return value, nil
+		case cadence.Resource:
+			value.ResourceType = translator.translate(value.ResourceType).(*cadence.ResourceType)
+			return value, nil
+		case cadence.Event:
+			value.EventType = translator.translate(value.EventType).(*cadence.EventType)
+			return value, nil
+		case cadence.Enum:
+			value.EnumType = translator.translate(value.EnumType).(*cadence.EnumType)
+			return value, nil
+		case cadence.Contract:
+			value.ContractType = translator.translate(value.ContractType).(*cadence.ContractType)
+			return value, nil
+		case cadence.Attachment:
+			value.AttachmentType = translator.translate(value.AttachmentType).(*cadence.AttachmentType)
+			return value, nil
+		}
+		return value, nil
+	})
+}
+
+func (m NetworkMapping) contractAddress(contract string, network string) (cadence.Address, bool) {
+	hex, ok := m[contract][network]
+	if !ok {
+		return cadence.Address{}, false
+	}
+	address, err := hexToAddress(hex)
+	if err != nil {
+		return cadence.Address{}, false
+	}
+	return *address, true
+}
+
+// typeTranslator translates the locations of types, translated types are remembered so that recursive types terminate
+type typeTranslator struct {
+	mapping    NetworkMapping
+	from       string
+	to         string
+	translated map[cadence.Type]cadence.Type
+}
+
+func (t *typeTranslator) location(location common.Location, qualifiedIdentifier string) common.Location {
+	addressLocation, ok := location.(common.AddressLocation)
+	if !ok {
+		return location
+	}
+	contract, _, _ := strings.Cut(qualifiedIdentifier, ".")
+	if !sameHex(t.mapping[contract][t.from], addressLocation.Address.Hex()) {
+		return location
+	}
+	address, ok := t.mapping.contractAddress(contract, t.to)
+	if !ok {
+		return location
+	}
+	return common.NewAddressLocation(nil, common.Address(address), addressLocation.Name)
+}
+
+func (t *typeTranslator) fields(fields []cadence.Field) []cadence.Field {
+	result := make([]cadence.Field, len(fields))
+	for i, field := range fields {
+		result[i] = cadence.Field{Identifier: field.Identifier, Type: t.translate(field.Type)}
+	}
+	return result
+}
+
+// translate a type, types cache their id so a new type is always created instead of changing a copy
+func (t *typeTranslator) translate(typ cadence.Type) cadence.Type {
+	if typ == nil {
+		return nil
+	}
+	// composites created without WithType have a nil pointer as type, it is kept as it is
+	if value := reflect.ValueOf(typ); value.Kind() == reflect.Pointer && value.IsNil() {
+		return typ
+	}
+	// only pointers are used as keys as other types might not be comparable
+	if reflect.TypeOf(typ).Kind() == reflect.Pointer {
+		if translated, ok := t.translated[typ]; ok {
+			return translated
+		}
+	}
+
+	switch typ := typ.(type) {
+	case *cadence.StructType:
+		result := cadence.NewStructType(t.location(typ.Location, typ.QualifiedIdentifier), typ.QualifiedIdentifier, nil, typ.Initializers)
+		t.translated[typ] = result
+		result.Fields = t.fields(typ.Fields)
+		return result
+	case *cadence.ResourceType:
+		result := cadence.NewResourceType(t.location(typ.Location, typ.QualifiedIdentifier), typ.QualifiedIdentifier, nil, typ.Initializers)
+		t.translated[typ] = result
+		result.Fields = t.fields(typ.Fields)
+		return result
+	case *cadence.EventType:
+		result := cadence.NewEventType(t.location(typ.Location, typ.QualifiedIdentifier), typ.QualifiedIdentifier, nil, typ.Initializer)
+		t.translated[typ] = result
+		result.Fields = t.fields(typ.Fields)
+		return result
+	case *cadence.EnumType:
+		result := cadence.NewEnumType(t.location(typ.Location, typ.QualifiedIdentifier), typ.QualifiedIdentifier, typ.RawType, nil, typ.Initializers)
+		t.translated[typ] = result
+		result.Fields = t.fields(typ.Fields)
+		return result
+	case *cadence.ContractType:
+		result := cadence.NewContractType(t.location(typ.Location, typ.QualifiedIdentifier), typ.QualifiedIdentifier, nil, typ.Initializers)
+		t.translated[typ] = result
+		result.Fields = t.fields(typ.Fields)
+		return result
+	case *cadence.AttachmentType:
+		result := cadence.NewAttachmentType(t.location(typ.Location, typ.QualifiedIdentifier), nil, typ.QualifiedIdentifier, nil, typ.Initializers)
+		t.translated[typ] = result
+		result.BaseType = t.translate(typ.BaseType)
+		result.Fields = t.fields(typ.Fields)
+		return result
+	case *cadence.StructInterfaceType:
+		result := cadence.NewStructInterfaceType(t.location(typ.Location, typ.QualifiedIdentifier), typ.QualifiedIdentifier, nil, typ.Initializers)
+		t.translated[typ] = result
+		result.Fields = t.fields(typ.Fields)
+		return result
+	case *cadence.ResourceInterfaceType:
+		result := cadence.NewResourceInterfaceType(t.location(typ.Location, typ.QualifiedIdentifier), typ.QualifiedIdentifier, nil, typ.Initializers)
+		t.translated[typ] = result
+		result.Fields = t.fields(typ.Fields)
+		return result
+	case *cadence.ContractInterfaceType:
+		result := cadence.NewContractInterfaceType(t.location(typ.Location, typ.QualifiedIdentifier), typ.QualifiedIdentifier, nil, typ.Initializers)
+		t.translated[typ] = result
+		result.Fields = t.fields(typ.Fields)
+		return result
+	case *cadence.OptionalType:
+		return cadence.NewOptionalType(t.translate(typ.Type))
+	case *cadence.VariableSizedArrayType:
+		return cadence.NewVariableSizedArrayType(t.translate(typ.ElementType))
+	case *cadence.ConstantSizedArrayType:
+		return cadence.NewConstantSizedArrayType(typ.Size, t.translate(typ.ElementType))
+	case *cadence.DictionaryType:
+		return cadence.NewDictionaryType(t.translate(typ.KeyType), t.translate(typ.ElementType))
+	case *cadence.CapabilityType:
+		return cadence.NewCapabilityType(t.translate(typ.BorrowType))
+	case *cadence.ReferenceType:
+		return cadence.NewReferenceType(typ.Authorized, t.translate(typ.Type))
+	case *cadence.RestrictedType:
+		restrictions := make([]cadence.Type, len(typ.Restrictions))
+		for i, restriction := range typ.Restrictions {
+			restrictions[i] = t.translate(restriction)
+		}
+		return cadence.NewRestrictedType(t.translate(typ.Type), restrictions)
+	}
+	return typ
+}
@@ -0,0 +1,193 @@
+package underflow
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testNetworkMapping(t *testing.T) NetworkMapping {
+	flowJson, err := ParseFlowJson([]byte(`{
+		"contracts": {
+			"Debug": {
+				"source": "./contracts/Debug.cdc",
+				"aliases": { "testnet": "0x1" }
+			}
+		},
+		"networks": { "emulator": "127.0.0.1:3569", "testnet": "access.devnet.nodes.onflow.org:9000" },
+		"accounts": { "emulator-account": { "address": "f8d6e0586b0a20c7" } },
+		"deployments": { "emulator": { "emulator-account": ["Debug"] } }
+	}`))
+	require.NoError(t, err)
+	return flowJson.NetworkMapping()
+}
+
+func TestNetworkMappingFromFlowJson(t *testing.T) {
+	mapping := testNetworkMapping(t)
+	assert.Equal(t, NetworkMapping{"Debug": {"emulator": "f8d6e0586b0a20c7", "testnet": "0000000000000001"}}, mapping)
+}
+
+func TestTranslateTypeID(t *testing.T) {
+	mapping := testNetworkMapping(t)
+
+	assert.Equal(t, "A.0000000000000001.Debug.Foo", mapping.TranslateTypeID("A.f8d6e0586b0a20c7.Debug.Foo", "emulator", "testnet"))
+	assert.Equal(t, "{String: [A.0000000000000001.Debug.Foo]}", mapping.TranslateTypeID("{String: [A.f8d6e0586b0a20c7.Debug.Foo]}", "emulator", "testnet"))
+	assert.Equal(t, "A.f8d6e0586b0a20c7.Other.Foo", mapping.TranslateTypeID("A.f8d6e0586b0a20c7.Other.Foo", "emulator", "testnet"), "unknown contracts are kept")
+	assert.Equal(t, "A.0000000000000002.Debug.Foo", mapping.TranslateTypeID("A.0000000000000002.Debug.Foo", "emulator", "testnet"), "other addresses are kept")
+}
+
+func TestNetworkMappingWithPrefixedHex(t *testing.T) {
+	mapping := NetworkMapping{"Debug": {"emulator": "0xF8D6E0586B0A20C7", "testnet": "0x0000000000000001"}}
+
+	assert.Equal(t, "A.0000000000000001.Debug.Foo", mapping.TranslateTypeID("A.f8d6e0586b0a20c7.Debug.Foo", "emulator", "testnet"))
+
+	emulatorAddress, err := hexToAddress("f8d6e0586b0a20c7")
+	require.NoError(t, err)
+	translated, ok := mapping.TranslateAddress(*emulatorAddress, "emulator", "testnet")
+	assert.True(t, ok)
+	assert.Equal(t, "0x0000000000000001", translated.String())
+
+	structType := &cadence.StructType{
+		Location:            common.NewAddressLocation(nil, common.Address(*emulatorAddress), "Debug"),
+		QualifiedIdentifier: "Debug.Foo",
+	}
+	result, err := mapping.TranslateValue(cadence.NewStruct(nil).WithType(structType), "emulator", "testnet")
+	require.NoError(t, err)
+	assert.Equal(t, "A.0000000000000001.Debug.Foo", result.Type().ID())
+
+	typeID, err := mapping.Resolver("testnet")("Debug_Foo")
+	require.NoError(t, err)
+	assert.Equal(t, "A.0000000000000001.Debug.Foo", typeID)
+}
+
+func TestTranslateResolver(t *testing.T) {
+	mapping := testNetworkMapping(t)
+	resolver, err := NewFlowJsonResolver("flow.json", "emulator")
+	require.NoError(t, err)
+
+	identifier, err := mapping.TranslateResolver(resolver, "emulator", "testnet")("Debug_Foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "A.0000000000000001.Debug.Foo", identifier)
+}
+
+func TestNetworkMappingResolver(t *testing.T) {
+	resolver := testNetworkMapping(t).Resolver("testnet")
+
+	identifier, err := resolver("Debug_Foo")
+	assert.NoError(t, err)
+	assert.Equal(t, "A.0000000000000001.Debug.Foo", identifier)
+
+	_, err = resolver("Other_Foo")
+	assert.ErrorContains(t, err, "contract Other has no address on network testnet")
+	_, err = resolver("Foo")
+	assert.ErrorContains(t, err, "type names must be on the form Contract_Struct")
+}
+
+func TestNetworkMappingTypeResolver(t *testing.T) {
+	mapping := NetworkMapping{"My_Token": {"testnet": "0000000000000002"}}
+	resolver := mapping.TypeResolver("testnet", map[string]string{
+		"My_Token_Vault": "My_Token.Vault",
+		"Broken":         "Broken",
+	})
+
+	identifier, err := resolver("My_Token_Vault")
+	assert.NoError(t, err)
+	assert.Equal(t, "A.0000000000000002.My_Token.Vault", identifier)
+
+	_, err = resolver("My_Token_Other")
+	assert.ErrorContains(t, err, "cannot resolve My_Token_Other, it is not a known type")
+	_, err = resolver("Broken")
+	assert.ErrorContains(t, err, "Broken is not on the form Contract.Struct")
+	_, err = mapping.TypeResolver("mainnet", map[string]string{"My_Token_Vault": "My_Token.Vault"})("My_Token_Vault")
+	assert.ErrorContains(t, err, "contract My_Token has no address on network mainnet")
+}
+
+func TestReplaceImports(t *testing.T) {
+	mapping := testNetworkMapping(t)
+
+	code, err := mapping.ReplaceImports(`import Debug from "./contracts/Debug.cdc"
+import "Debug"
+import FungibleToken from 0xf233dcee88fe0abe
+
+pub fun main(): String {
+	return "import Debug from here"
+}`, "emulator")
+	require.NoError(t, err)
+	assert.Equal(t, `import Debug from 0xf8d6e0586b0a20c7
+import Debug from 0xf8d6e0586b0a20c7
+import FungibleToken from 0xf233dcee88fe0abe
+
+pub fun main(): String {
+	return "import Debug from here"
+}`, code)
+
+	_, err = mapping.ReplaceImports(`import Other from "./Other.cdc"`, "emulator")
+	assert.ErrorContains(t, err, "cannot import Other, the contract has no address on network emulator")
+}
+
+func TestTranslateValue(t *testing.T) {
+	mapping := testNetworkMapping(t)
+
+	emulatorAddress, err := hexToAddress("f8d6e0586b0a20c7")
+	require.NoError(t, err)
+
+	structType := &cadence.StructType{
+		Location:            common.NewAddressLocation(nil, common.Address(*emulatorAddress), "Debug"),
+		QualifiedIdentifier: "Debug.Foo",
+		Fields: []cadence.Field{
+			{Identifier: "owner", Type: cadence.AddressType{}},
+		},
+	}
+	value := cadence.NewArray([]cadence.Value{
+		cadence.NewStruct([]cadence.Value{*emulatorAddress}).WithType(structType),
+		cadence.NewTypeValue(structType),
+	})
+
+	result, err := mapping.TranslateValue(value, "emulator", "testnet")
+	require.NoError(t, err)
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"<A.0000000000000001.Debug.Foo>": map[string]interface{}{"owner": "0xf8d6e0586b0a20c7"}},
+		"A.0000000000000001.Debug.Foo",
+	}, CadenceValueToInterfaceWithOption(result, Options{WrapWithComplexTypes: true}), "addresses are user data and are kept")
+
+	result, err = mapping.TranslateValueAndAddresses(value, "emulator", "testnet")
+	require.NoError(t, err)
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"<A.0000000000000001.Debug.Foo>": map[string]interface{}{"owner": "0x0000000000000001"}},
+		"A.0000000000000001.Debug.Foo",
+	}, CadenceValueToInterfaceWithOption(result, Options{WrapWithComplexTypes: true}))
+
+	assert.Equal(t, "A.f8d6e0586b0a20c7.Debug.Foo", structType.ID(), "input types are not modified")
+
+	untyped := cadence.NewArray([]cadence.Value{
+		cadence.NewStruct([]cadence.Value{*emulatorAddress}),
+		cadence.NewEvent(nil),
+		cadence.NewPathCapability(*emulatorAddress, cadence.Path{Domain: common.PathDomainPublic, Identifier: "foo"}, nil),
+	})
+	result, err = mapping.TranslateValueAndAddresses(untyped, "emulator", "testnet")
+	require.NoError(t, err)
+	values := result.(cadence.Array).Values
+	assert.Nil(t, values[0].(cadence.Struct).StructType)
+	assert.Equal(t, "0x0000000000000001", values[0].(cadence.Struct).Fields[0].String())
+	assert.Nil(t, values[1].(cadence.Event).EventType)
+	assert.Equal(t, "0x0000000000000001", values[2].(cadence.PathCapability).Address.String())
+}
+
+func TestTranslateTypeIDInOutput(t *testing.T) {
+	mapping := testNetworkMapping(t)
+	val, err := InputToCadence(Debug_Foo{Bar: "foo"}, func(string) (string, error) {
+		return "A.f8d6e0586b0a20c7.Debug.Foo", nil
+	})
+	require.NoError(t, err)
+
+	result := CadenceValueToInterfaceWithOption(val, Options{
+		WrapWithComplexTypes: true,
+		TranslateTypeID:      mapping.TypeIDTranslator("emulator", "testnet"),
+	})
+	assert.Equal(t, map[string]interface{}{"<A.0000000000000001.Debug.Foo>": map[string]interface{}{"bar": "foo"}}, result)
+}
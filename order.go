@@ -0,0 +1,89 @@
+package underflow
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/onflow/cadence"
+)
+
+// / Order cadence dictionary keys the way cadence compares them, numbers by value, strings and characters lexically, addresses by their bytes and false before true
+// /  Keys of different types are in one total order: nil first, then numbers by value and equal numbers by their type id, then the other keys by their type id
+func CadenceKeyLess(a, b cadence.Value) bool {
+	a = unwrapOptional(a)
+	b = unwrapOptional(b)
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+
+	aNumber, aIsNumber := numberKey(a)
+	bNumber, bIsNumber := numberKey(b)
+	if aIsNumber != bIsNumber {
+		return aIsNumber
+	}
+	aType, bType := keyTypeID(a), keyTypeID(b)
+	if aIsNumber {
+		if c := aNumber.Cmp(bNumber); c != 0 {
+			return c < 0
+		}
+		return aType < bType
+	}
+	if aType != bType {
+		return aType < bType
+	}
+
+	switch a := a.(type) {
+	case cadence.String:
+		return a < b.(cadence.String)
+	case cadence.Character:
+		return a < b.(cadence.Character)
+	case cadence.Address:
+		bAddress := b.(cadence.Address)
+		return bytes.Compare(a[:], bAddress[:]) < 0
+	case cadence.Bool:
+		return !bool(a) && bool(b.(cadence.Bool))
+	case cadence.Path:
+		bPath := b.(cadence.Path)
+		if a.Domain != bPath.Domain {
+			return a.Domain < bPath.Domain
+		}
+		return a.Identifier < bPath.Identifier
+	}
+	return a.String() < b.String()
+}
+
+func unwrapOptional(value cadence.Value) cadence.Value {
+	for {
+		optional, ok := value.(cadence.Optional)
+		if !ok {
+			return value
+		}
+		value = optional.Value
+	}
+}
+
+func keyTypeID(value cadence.Value) string {
+	if value.Type() == nil {
+		return ""
+	}
+	return value.Type().ID()
+}
+
+// the numeric value of integer and fixed point keys
+func numberKey(value cadence.Value) (*big.Rat, bool) {
+	switch value.(type) {
+	case cadence.Int, cadence.Int8, cadence.Int16, cadence.Int32, cadence.Int64, cadence.Int128, cadence.Int256,
+		cadence.UInt, cadence.UInt8, cadence.UInt16, cadence.UInt32, cadence.UInt64, cadence.UInt128, cadence.UInt256,
+		cadence.Word8, cadence.Word16, cadence.Word32, cadence.Word64, cadence.Fix64, cadence.UFix64:
+		return new(big.Rat).SetString(value.String())
+	}
+	return nil, false
+}
+
+// sort the pairs of a dictionary in place by their keys
+func sortKeyValuePairs(pairs []cadence.KeyValuePair, less func(a, b cadence.Value) bool) {
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return less(pairs[i].Key, pairs[j].Key)
+	})
+}
@@ -0,0 +1,98 @@
+package underflow
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDictionaryInputIsSorted(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "strings", value: map[string]int{"b": 2, "c": 3, "a": 1, "aa": 4}, want: `{"a": 1, "aa": 4, "b": 2, "c": 3}`},
+		{name: "numbers", value: map[int]bool{10: true, -1: false, 2: true, 100: false}, want: `{-1: false, 2: true, 10: true, 100: false}`},
+		{name: "addresses", value: map[Address]string{{0x2}: "b", {0x1}: "a"}, want: `{0x0100000000000000: "a", 0x0200000000000000: "b"}`},
+		{name: "bools", value: map[bool]int{true: 1, false: 0}, want: `{false: 0, true: 1}`},
+		{name: "fixed point", value: map[float64]int{10.5: 2, 2.25: 1}, want: `{2.25000000: 1, 10.50000000: 2}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				value, err := InputToCadence(test.value, nil)
+				require.NoError(t, err)
+				assert.Equal(t, test.want, value.String())
+			}
+		})
+	}
+}
+
+func TestDictionaryInputCustomOrder(t *testing.T) {
+	reverse := func(a, b cadence.Value) bool {
+		return CadenceKeyLess(b, a)
+	}
+
+	value, err := InputToCadenceWithOption(map[string]int{"a": 1, "b": 2, "c": 3}, nil, Options{DictionaryKeyLess: reverse})
+	require.NoError(t, err)
+	assert.Equal(t, `{"c": 3, "b": 2, "a": 1}`, value.String())
+
+	type Debug_Sorted struct {
+		Prices map[string]float64 `cadence:"prices,type={String: UFix64}"`
+	}
+	value, err = InputToCadenceWithOption(Debug_Sorted{Prices: map[string]float64{"a": 1, "b": 2}}, func(string) (string, error) {
+		return "A.f8d6e0586b0a20c7.Debug.Sorted", nil
+	}, Options{DictionaryKeyLess: reverse})
+	require.NoError(t, err)
+	assert.Equal(t, `{"b": 2.00000000, "a": 1.00000000}`, value.(cadence.Struct).Fields[0].String())
+}
+
+func TestCadenceKeyLess(t *testing.T) {
+	assert.True(t, CadenceKeyLess(cadence.NewUInt8(2), cadence.NewInt(10)))
+	assert.False(t, CadenceKeyLess(cadence.NewInt(10), cadence.NewUInt8(2)))
+	assert.True(t, CadenceKeyLess(cadence.NewOptional(nil), cadence.String("a")))
+	assert.True(t, CadenceKeyLess(cadence.NewOptional(cadence.String("a")), cadence.String("b")))
+	assert.True(t, CadenceKeyLess(
+		cadence.Path{Domain: common.PathDomainStorage, Identifier: "a"},
+		cadence.Path{Domain: common.PathDomainStorage, Identifier: "b"},
+	))
+}
+
+func TestCadenceKeyLessMixedTypes(t *testing.T) {
+	want := []cadence.Value{
+		cadence.NewOptional(nil),
+		cadence.NewInt(3),
+		cadence.NewUInt8(3),
+		cadence.NewInt(5),
+		cadence.Bool(true),
+		cadence.String("a"),
+		cadence.String("x"),
+	}
+
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		keys := append([]cadence.Value{}, want...)
+		random.Shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+		pairs := []cadence.KeyValuePair{}
+		for _, key := range keys {
+			pairs = append(pairs, cadence.KeyValuePair{Key: key, Value: cadence.NewInt(0)})
+		}
+		sortKeyValuePairs(pairs, CadenceKeyLess)
+
+		got := []cadence.Value{}
+		for _, pair := range pairs {
+			got = append(got, pair.Key)
+		}
+		assert.Equal(t, want, got)
+	}
+
+	assert.True(t, CadenceKeyLess(cadence.NewUInt8(3), cadence.NewInt(5)))
+	assert.True(t, CadenceKeyLess(cadence.NewInt(5), cadence.String("x")))
+	assert.False(t, CadenceKeyLess(cadence.String("x"), cadence.NewUInt8(3)))
+}
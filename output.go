@@ -3,6 +3,7 @@ package underflow
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"strconv"
 
 	"github.com/onflow/cadence"
@@ -12,12 +13,44 @@ type Options struct {
 	IncludeEmptyValues       bool
 	WrapWithComplexTypes     bool
 	UseStringForFixedNumbers bool
+	// render addresses that are in the address book with their name instead of the hex string
+	AddressBook AddressBook
+	// render addresses in the address book as name (0x...)
+	AddressBookIncludeHex bool
+	// rewrite the type ids in the output, for instance to another network with NetworkMapping.TypeIDTranslator
+	TranslateTypeID func(string) string
+	// how floats are rounded to the 8 decimals of UFix64 and Fix64 when converting input
+	FloatRounding big.RoundingMode
+	// the order of the keys in dictionaries created from go maps, CadenceKeyLess is used if it is nil
+	DictionaryKeyLess func(a, b cadence.Value) bool
+	// resolves the names of go structs into cadence type ids, it is used if no resolver is sendt in
+	Resolver InputResolver
 }
 
 var defaultOptions = Options{
 	IncludeEmptyValues:       false,
 	WrapWithComplexTypes:     false,
 	UseStringForFixedNumbers: false,
+	AddressBook:              nil,
+	AddressBookIncludeHex:    false,
+	TranslateTypeID:          nil,
+	FloatRounding:            big.ToNearestEven,
+	DictionaryKeyLess:        nil,
+	Resolver:                 nil,
+}
+
+func (opt Options) keyLess() func(a, b cadence.Value) bool {
+	if opt.DictionaryKeyLess == nil {
+		return CadenceKeyLess
+	}
+	return opt.DictionaryKeyLess
+}
+
+func (opt Options) typeID(typ cadence.Type) string {
+	if opt.TranslateTypeID == nil {
+		return typ.ID()
+	}
+	return opt.TranslateTypeID(typ.ID())
 }
 
 // / This method converts a cadence.Value to an json string representing that value
@@ -46,78 +79,157 @@ func CadenceValueToInterface(field cadence.Value) interface{} {
 
 // / Convert a cadence value into a interface{} structure for easier consumption in go with options
 func CadenceValueToInterfaceWithOption(field cadence.Value, opt Options) interface{} {
-	if field == nil {
+	builder := &interfaceBuilder{opt: opt}
+	// the builder never returns an error
+	_ = Walk(field, builder)
+	return builder.result
+}
+
+// interfaceBuilder is a Visitor that builds the interface{} representation of a value
+//
+//	complex values get a frame on a stack when entered that their children are added to, the frame is converted when the value is left
+type interfaceBuilder struct {
+	opt    Options
+	stack  []*interfaceFrame
+	result interface{}
+}
+
+type interfaceFrame struct {
+	fields map[string]interface{}
+	items  []interface{}
+	inner  interface{}
+	value  cadence.Value
+}
+
+func isComplexValue(value cadence.Value) bool {
+	switch value.(type) {
+	case cadence.Optional, cadence.Dictionary, cadence.Struct, cadence.Array, cadence.Event, cadence.Resource, cadence.PathCapability:
+		return true
+	}
+	return false
+}
+
+func (b *interfaceBuilder) Enter(path ValuePath, value cadence.Value) error {
+	if isComplexValue(value) {
+		b.stack = append(b.stack, &interfaceFrame{value: value, fields: map[string]interface{}{}})
 		return nil
 	}
 
-	switch field := field.(type) {
+	b.add(path, leafToInterface(value, b.opt))
+	return SkipValue
+}
+
+func (b *interfaceBuilder) Leave(path ValuePath, value cadence.Value) error {
+	if !isComplexValue(value) {
+		return nil
+	}
+
+	frame := b.stack[len(b.stack)-1]
+	b.stack = b.stack[:len(b.stack)-1]
+	b.add(path, b.complexToInterface(frame))
+	return nil
+}
+
+// add a converted value to the frame of its parent
+func (b *interfaceBuilder) add(path ValuePath, value interface{}) {
+	if len(b.stack) == 0 {
+		b.result = value
+		return
+	}
+
+	parent := b.stack[len(b.stack)-1]
+	element, _ := path.Last()
+	switch parent.value.(type) {
 	case cadence.Optional:
-		return CadenceValueToInterfaceWithOption(field.Value, opt)
+		parent.inner = value
+	case cadence.PathCapability:
+		parent.fields[element.Field] = value
+	case cadence.Array:
+		if value != nil || b.opt.IncludeEmptyValues {
+			parent.items = append(parent.items, value)
+		}
 	case cadence.Dictionary:
-		// fmt.Println("is dict ", field.ToGoValue(), " ", field.String())
-		result := map[string]interface{}{}
-		for _, item := range field.Pairs {
-			value := CadenceValueToInterfaceWithOption(item.Value, opt)
-			key := getAndUnquoteString(item.Key)
-
-			if key != "" {
-				if value != nil || opt.IncludeEmptyValues {
-					result[key] = value
-				}
-			}
+		if element.Kind != PathValue {
+			return
+		}
+		key := getAndUnquoteString(element.Key)
+		if address, ok := element.Key.(cadence.Address); ok {
+			key = b.opt.AddressBook.Format(address, b.opt.AddressBookIncludeHex)
+		}
+		if key != "" && (value != nil || b.opt.IncludeEmptyValues) {
+			parent.fields[key] = value
 		}
+	default:
+		if value != nil || b.opt.IncludeEmptyValues {
+			parent.fields[element.Field] = value
+		}
+	}
+}
 
-		if len(result) == 0 && !opt.IncludeEmptyValues {
+func (b *interfaceBuilder) complexToInterface(frame *interfaceFrame) interface{} {
+	opt := b.opt
+	switch field := frame.value.(type) {
+	case cadence.Optional:
+		return frame.inner
+	case cadence.Dictionary:
+		if len(frame.fields) == 0 && !opt.IncludeEmptyValues {
 			return nil
 		}
-		return result
+		return frame.fields
 	case cadence.Struct:
-		// fmt.Println("is struct ", field.ToGoValue(), " ", field.String())
-		result := map[string]interface{}{}
-		subStructNames := field.StructType.Fields
+		if len(frame.fields) == 0 && !opt.IncludeEmptyValues {
+			return nil
+		}
 
-		for j, subField := range field.Fields {
-			value := CadenceValueToInterfaceWithOption(subField, opt)
-			key := subStructNames[j].Identifier
+		if !opt.WrapWithComplexTypes || field.StructType == nil {
+			return frame.fields
+		}
 
-			//	fmt.Println("struct ", key, "value", value)
-			if value != nil || opt.IncludeEmptyValues {
-				result[key] = value
-			}
+		return map[string]interface{}{
+			fmt.Sprintf("<%s>", opt.typeID(field.StructType)): frame.fields,
 		}
-		if len(result) == 0 && !opt.IncludeEmptyValues {
+	case cadence.Array:
+		if len(frame.items) == 0 && !opt.IncludeEmptyValues {
 			return nil
 		}
+		return frame.items
+	case cadence.Event:
+		if !opt.WrapWithComplexTypes || field.EventType == nil {
+			return frame.fields
+		}
 
-		if !opt.WrapWithComplexTypes {
-			return result
+		return map[string]interface{}{
+			fmt.Sprintf("<%s>", opt.typeID(field.EventType)): frame.fields,
+		}
+	case cadence.Resource:
+		if !opt.WrapWithComplexTypes || field.ResourceType == nil {
+			return frame.fields
 		}
 
 		return map[string]interface{}{
-			fmt.Sprintf("<%s>", field.StructType.ID()): result,
+			fmt.Sprintf("<@%s>", opt.typeID(field.ResourceType)): frame.fields,
 		}
-	case cadence.Array:
-		// fmt.Println("is array ", field.ToGoValue(), " ", field.String())
-		var result []interface{}
-		for _, item := range field.Values {
-			value := CadenceValueToInterfaceWithOption(item, opt)
-			//	fmt.Printf("%+v\n", value)
-			if value != nil || opt.IncludeEmptyValues {
-				result = append(result, value)
-			}
-		}
-		if len(result) == 0 && !opt.IncludeEmptyValues {
-			return nil
+	case cadence.PathCapability:
+		if !opt.WrapWithComplexTypes || field.BorrowType == nil {
+			return frame.fields
+		}
+		return map[string]interface{}{
+			fmt.Sprintf("<Capability<%s>>", opt.typeID(field.BorrowType)): frame.fields,
 		}
-		return result
+	}
+	return nil
+}
 
+// convert a value that is not complex into a interface{}
+func leafToInterface(field cadence.Value, opt Options) interface{} {
+	switch field := field.(type) {
 	case cadence.Int:
 		return field.Int()
 	case cadence.Address:
-		return field.String()
+		return opt.AddressBook.Format(field, opt.AddressBookIncludeHex)
 	case cadence.TypeValue:
 		// fmt.Println("is type ", field.ToGoValue(), " ", field.String())
-		return field.StaticType.ID()
+		return opt.typeID(field.StaticType)
 	case cadence.String:
 		// fmt.Println("is string ", field.ToGoValue(), " ", field.String())
 		value := getAndUnquoteString(field)
@@ -140,59 +252,6 @@ func CadenceValueToInterfaceWithOption(field cadence.Value, opt Options) interfa
 		}
 		float, _ := strconv.ParseFloat(field.String(), 64)
 		return float
-	case cadence.Event:
-		result := map[string]interface{}{}
-
-		for i, subField := range field.Fields {
-			value := CadenceValueToInterfaceWithOption(subField, opt)
-			if value != nil || opt.IncludeEmptyValues {
-				result[field.EventType.Fields[i].Identifier] = value
-			}
-		}
-
-		if !opt.WrapWithComplexTypes {
-			return result
-		}
-
-		return map[string]interface{}{
-			fmt.Sprintf("<%s>", field.EventType.ID()): result,
-		}
-
-	case cadence.Resource:
-
-		fields := map[string]interface{}{}
-		// fmt.Println("is struct ", field.ToGoValue(), " ", field.String())
-		subStructNames := field.ResourceType.Fields
-
-		for j, subField := range field.Fields {
-			value := CadenceValueToInterfaceWithOption(subField, opt)
-			key := subStructNames[j].Identifier
-
-			//	fmt.Println("struct ", key, "value", value)
-			if value != nil || opt.IncludeEmptyValues {
-				fields[key] = value
-			}
-		}
-
-		if !opt.WrapWithComplexTypes {
-			return fields
-		}
-
-		return map[string]interface{}{
-			fmt.Sprintf("<@%s>", field.ResourceType.ID()): fields,
-		}
-	case cadence.PathCapability:
-
-		fields := map[string]interface{}{
-			"address": CadenceValueToInterfaceWithOption(field.Address, opt),
-			"path":    CadenceValueToInterfaceWithOption(field.Path, opt),
-		}
-		if !opt.WrapWithComplexTypes {
-			return fields
-		}
-		return map[string]interface{}{
-			fmt.Sprintf("<Capability<%s>>", field.BorrowType.ID()): fields,
-		}
 	default:
 		// fmt.Println("is fallthrough ", field.ToGoValue(), " ", field.String())
 
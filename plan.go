@@ -0,0 +1,260 @@
+package underflow
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fatih/structtag"
+	"github.com/onflow/cadence"
+)
+
+// how the fields of a go struct are converted, it only depends on the go type so it is cached
+type structPlan struct {
+	fields []fieldPlan
+	err    error
+}
+
+type fieldPlan struct {
+	// the index sequence of the field, it is longer than one for fields promoted from embedded structs
+	index []int
+	field reflect.StructField
+	name  string
+	tag   *structtag.Tag
+	// the type in the type= tag option
+	explicitType cadence.Type
+	// if the name is set in the tag, it wins over other promoted fields with the same name
+	tagged bool
+}
+
+var structPlans sync.Map // reflect.Type -> *structPlan
+
+func structPlanFor(inputType reflect.Type) (*structPlan, error) {
+	if plan, ok := structPlans.Load(inputType); ok {
+		return plan.(*structPlan), plan.(*structPlan).err
+	}
+
+	plan := &structPlan{}
+	plan.fields, plan.err = structFields(inputType)
+
+	actual, _ := structPlans.LoadOrStore(inputType, plan)
+	return actual.(*structPlan), actual.(*structPlan).err
+}
+
+// the fields of a struct the way encoding/json finds them, unexported fields are skipped and the fields of anonymous embedded structs are promoted
+//
+//	an embedded struct with a name in the tag or the nested option is a field of its own, if promoted fields have the same name the least nested wins
+//	and fields with the same name at the same depth are dropped, also when the same struct is embedded twice at that depth.
+//	Unlike encoding/json a nil embedded pointer is an error when converting to cadence instead of skipping its fields,
+//	since a cadence struct type has a fixed set of fields
+func structFields(inputType reflect.Type) ([]fieldPlan, error) {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	fields := []fieldPlan{}
+	depths := map[string]int{}
+	current := []embedded{{typ: inputType}}
+	visited := map[reflect.Type]bool{}
+	for depth := 0; len(current) > 0; depth++ {
+		next := []embedded{}
+		// a struct embedded twice at the same depth is walked twice so its fields are ambiguous and dropped
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+
+			for i := 0; i < e.typ.NumField(); i++ {
+				field := e.typ.Field(i)
+				fieldType := field.Type
+				if fieldType.Kind() == reflect.Pointer {
+					fieldType = fieldType.Elem()
+				}
+				if field.Anonymous {
+					if !field.IsExported() && fieldType.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !field.IsExported() {
+					continue
+				}
+
+				name, tag, err := structFieldTag(field)
+				if err != nil {
+					return nil, err
+				}
+				if name == "-" {
+					continue
+				}
+				tagged := tag != nil && tag.Name != ""
+				index := append(append([]int{}, e.index...), i)
+
+				if field.Anonymous && !tagged && !tagHasOption(tag, "nested") && isEmbeddableStruct(fieldType) {
+					next = append(next, embedded{typ: fieldType, index: index})
+					continue
+				}
+
+				var explicitType cadence.Type
+				if tag != nil {
+					explicitType, err = tagCadenceType(tag.Options)
+					if err != nil {
+						return nil, fmt.Errorf("field %s: %w", field.Name, err)
+					}
+				}
+				if explicitType != nil {
+					// cadence types compute their id lazily, do it before the plan is shared between goroutines
+					explicitType.ID()
+				}
+
+				if _, ok := depths[name]; !ok {
+					depths[name] = depth
+				}
+				fields = append(fields, fieldPlan{
+					index:        index,
+					field:        field,
+					name:         name,
+					tag:          tag,
+					explicitType: explicitType,
+					tagged:       tagged,
+				})
+			}
+		}
+		for _, e := range current {
+			visited[e.typ] = true
+		}
+		current = next
+	}
+
+	return dominantFields(fields, depths), nil
+}
+
+// structs that are converted as a whole are not flattened when embedded
+func isEmbeddableStruct(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ != bigIntType && typ != timeType
+}
+
+// keep the least nested field for every name, if there are several at that depth the tagged one wins and if that does not decide it they are all dropped
+func dominantFields(fields []fieldPlan, depths map[string]int) []fieldPlan {
+	candidates := map[string][]fieldPlan{}
+	for _, field := range fields {
+		if len(field.index)-1 == depths[field.name] {
+			candidates[field.name] = append(candidates[field.name], field)
+		}
+	}
+
+	result := []fieldPlan{}
+	for _, field := range fields {
+		fieldsWithName := candidates[field.name]
+		if len(field.index)-1 != depths[field.name] {
+			continue
+		}
+		if len(fieldsWithName) == 1 {
+			result = append(result, field)
+			continue
+		}
+
+		tagged := []fieldPlan{}
+		for _, candidate := range fieldsWithName {
+			if candidate.tagged {
+				tagged = append(tagged, candidate)
+			}
+		}
+		if len(tagged) == 1 && field.tagged {
+			result = append(result, field)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i].index, result[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return result
+}
+
+// the value of a field, promoted fields behind a nil embedded pointer can not be converted
+func fieldByIndex(value reflect.Value, field fieldPlan) (reflect.Value, error) {
+	for i, index := range field.index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				return reflect.Value{}, fmt.Errorf("cannot convert field %s, the embedded %s is nil", field.field.Name, value.Type())
+			}
+			value = value.Elem()
+		}
+		value = value.Field(index)
+	}
+	return value, nil
+}
+
+// the cadence name and tag of a struct field, the json tag is used if there is no cadence tag and the name defaults to the lowercase field name
+func structFieldTag(field reflect.StructField) (string, *structtag.Tag, error) {
+	tags, err := structtag.Parse(string(field.Tag))
+	if err != nil {
+		return "", nil, err
+	}
+
+	name := ""
+	tag, err := tags.Get("cadence")
+	if err != nil {
+		tag, _ = tags.Get("json")
+	}
+	if tag != nil {
+		name = tag.Name
+	}
+
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, tag, nil
+}
+
+type staticTypeKey struct {
+	inputType reflect.Type
+	options   string
+}
+
+// static types are cached, types with structs are checked against the resolver before they are reused
+var staticTypes sync.Map // staticTypeKey -> staticType
+
+func staticTypeKeyFor(inputType reflect.Type, tag *structtag.Tag) staticTypeKey {
+	key := staticTypeKey{inputType: inputType}
+	if tag != nil {
+		key.options = strings.Join(tag.Options, ",")
+	}
+	return key
+}
+
+// the qualified identifier of a struct, a nil resolver is an error since structs cannot be named without one
+func resolveStructName(resolver InputResolver, inputType reflect.Type) (string, error) {
+	if resolver == nil {
+		return "", fmt.Errorf("cannot convert %s to cadence without a resolver", inputType)
+	}
+	return resolver(inputType.Name())
+}
+
+// a resolver that only calls the given resolver once for every name, it is used for the duration of one conversion
+func memoizeResolver(resolver InputResolver) InputResolver {
+	if resolver == nil {
+		return nil
+	}
+
+	type resolved struct {
+		identifier string
+		err        error
+	}
+	cache := map[string]resolved{}
+	return func(name string) (string, error) {
+		if result, ok := cache[name]; ok {
+			return result.identifier, result.err
+		}
+		identifier, err := resolver(name)
+		cache[name] = resolved{identifier: identifier, err: err}
+		return identifier, err
+	}
+}
@@ -0,0 +1,233 @@
+package underflow
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Debug_Listing struct {
+	ID      uint64             `cadence:"id"`
+	Seller  string             `cadence:"seller,cadenceAddress"`
+	Price   float64            `cadence:"price"`
+	Tags    []string           `cadence:"tags"`
+	Royalty map[string]float64 `cadence:"royalty"`
+	Note    *string            `cadence:"note"`
+}
+
+func benchmarkListings(n int) []Debug_Listing {
+	listings := make([]Debug_Listing, n)
+	for i := range listings {
+		listings[i] = Debug_Listing{
+			ID:      uint64(i),
+			Seller:  "0xf8d6e0586b0a20c7",
+			Price:   float64(i) + 0.5,
+			Tags:    []string{"art", "rare"},
+			Royalty: map[string]float64{"creator": 0.05},
+		}
+	}
+	return listings
+}
+
+func benchmarkResolver(name string) (string, error) {
+	return fmt.Sprintf("A.f8d6e0586b0a20c7.Debug.%s", name[len("Debug_"):]), nil
+}
+
+func BenchmarkInputToCadenceStruct(b *testing.B) {
+	listing := benchmarkListings(1)[0]
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := InputToCadence(listing, benchmarkResolver); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkInputToCadenceSlice(b *testing.B) {
+	listings := benchmarkListings(1000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := InputToCadence(listings, benchmarkResolver); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestInputToCadenceConcurrent(t *testing.T) {
+	listings := benchmarkListings(10)
+	value, err := InputToCadence(listings, benchmarkResolver)
+	require.NoError(t, err)
+	want := value.String()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := InputToCadence(listings, benchmarkResolver)
+			assert.NoError(t, err)
+			assert.Equal(t, want, value.String())
+		}()
+	}
+	wg.Wait()
+}
+
+func TestResolverIsCalledOncePerType(t *testing.T) {
+	calls := 0
+	_, err := InputToCadence(benchmarkListings(100), func(name string) (string, error) {
+		calls++
+		return benchmarkResolver(name)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestInputToCadenceConcurrentTypes(t *testing.T) {
+	type Debug_Shared struct {
+		Prices map[string]float64 `cadence:"prices,type={String: UFix64}"`
+		Tags   [][]string         `cadence:"tags"`
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := InputToCadence(Debug_Shared{Tags: [][]string{{"a"}}}, benchmarkResolver)
+			assert.NoError(t, err)
+			fields := value.(cadence.Struct).StructType.Fields
+			assert.Equal(t, "{String:UFix64}", fields[0].Type.ID())
+			assert.Equal(t, "[[String]]", fields[1].Type.ID())
+		}()
+	}
+	wg.Wait()
+}
+
+type TokenID uint64
+
+type Rarity string
+
+type Debug_Defined struct {
+	ID     TokenID          `cadence:"id"`
+	Rarity Rarity           `cadence:"rarity"`
+	Flag   bool             `cadence:"flag"`
+	Scores map[Rarity]int16 `cadence:"scores"`
+	Any    interface{}      `cadence:"any"`
+	hidden string
+}
+
+func TestDefinedTypesInputToCadence(t *testing.T) {
+	value, err := InputToCadence(Debug_Defined{
+		ID:     42,
+		Rarity: "epic",
+		Flag:   true,
+		Scores: map[Rarity]int16{"a": -1},
+		Any:    TokenID(7),
+		hidden: "secret",
+	}, benchmarkResolver)
+	require.NoError(t, err)
+
+	structValue := value.(cadence.Struct)
+	assert.Equal(t, []string{"42", `"epic"`, "true", `{"a": -1}`, "7"}, cadenceStrings(structValue.Fields))
+	assert.Equal(t, 5, len(structValue.StructType.Fields))
+	assert.IsType(t, cadence.UInt64(0), structValue.Fields[0])
+}
+
+type Debug_Base struct {
+	ID    uint64 `cadence:"id"`
+	Owner string `cadence:"owner"`
+}
+
+type debug_audit struct {
+	Created string `cadence:"created"`
+}
+
+type Debug_Meta struct {
+	Name string `cadence:"name"`
+}
+
+type Debug_Embedding struct {
+	Debug_Base
+	*Debug_Meta
+	debug_audit
+	Owner string     `cadence:"owner"`
+	Base  Debug_Base `cadence:"base"`
+}
+
+func TestEmbeddedStructsAreFlattened(t *testing.T) {
+	value, err := InputToCadence(Debug_Embedding{
+		Debug_Base:  Debug_Base{ID: 1, Owner: "shadowed"},
+		Debug_Meta:  &Debug_Meta{Name: "meta"},
+		debug_audit: debug_audit{Created: "today"},
+		Owner:       "outer",
+	}, benchmarkResolver)
+	require.NoError(t, err)
+
+	structValue := value.(cadence.Struct)
+	names := []string{}
+	for _, field := range structValue.StructType.Fields {
+		names = append(names, field.Identifier)
+	}
+	assert.Equal(t, []string{"id", "name", "created", "owner", "base"}, names)
+	assert.Equal(t, []string{"1", `"meta"`, `"today"`, `"outer"`}, cadenceStrings(structValue.Fields[:4]))
+
+	_, err = InputToCadence(Debug_Embedding{}, benchmarkResolver)
+	assert.ErrorContains(t, err, "cannot convert field Name, the embedded *underflow.Debug_Meta is nil")
+}
+
+func TestEmbeddedStructOptOut(t *testing.T) {
+	type Debug_Nested struct {
+		Debug_Base `cadence:",nested"`
+		Debug_Meta `cadence:"meta"`
+	}
+
+	value, err := InputToCadence(Debug_Nested{Debug_Base: Debug_Base{ID: 1}, Debug_Meta: Debug_Meta{Name: "meta"}}, benchmarkResolver)
+	require.NoError(t, err)
+
+	structValue := value.(cadence.Struct)
+	assert.Equal(t, "debug_base", structValue.StructType.Fields[0].Identifier)
+	assert.Equal(t, "meta", structValue.StructType.Fields[1].Identifier)
+	assert.Equal(t, "A.f8d6e0586b0a20c7.Debug.Meta", structValue.StructType.Fields[1].Type.ID())
+}
+
+func TestEmbeddedFieldConflicts(t *testing.T) {
+	type Debug_Other struct {
+		ID    uint64 `cadence:"id"`
+		Owner string
+	}
+	type Debug_Conflict struct {
+		Debug_Base
+		Debug_Other
+	}
+
+	typ, err := CadenceTypeOf(reflect.TypeOf(Debug_Conflict{}), benchmarkResolver)
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, field := range typ.(*cadence.StructType).Fields {
+		names = append(names, field.Identifier)
+	}
+	// both have a tagged id so it is dropped, owner is only tagged in Debug_Base so it wins
+	assert.Equal(t, []string{"owner"}, names)
+
+	// Debug_Base is promoted through both, so like encoding/json its fields are ambiguous
+	type Debug_Left struct{ Debug_Base }
+	type Debug_Right struct{ Debug_Base }
+	type Debug_Twice struct {
+		Debug_Left
+		Debug_Right
+		Name string `cadence:"name"`
+	}
+	typ, err = CadenceTypeOf(reflect.TypeOf(Debug_Twice{}), benchmarkResolver)
+	require.NoError(t, err)
+	names = []string{}
+	for _, field := range typ.(*cadence.StructType).Fields {
+		names = append(names, field.Identifier)
+	}
+	assert.Equal(t, []string{"name"}, names)
+}
@@ -0,0 +1,159 @@
+package underflow
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+)
+
+// / How a SQLValue is stored in the database
+type SQLFormat int
+
+const (
+	// store the value as JSON-CDC, it can be read back without loss
+	SQLJsonCdc SQLFormat = iota
+	// store the value as the terse json from CadenceValueToInterfaceWithOption, it is easier to query but the types are lost
+	SQLTerseJson
+)
+
+// / A cadence value that can be stored in and read from a database with database/sql
+// /  Values stored as terse json are read back as strings, numbers, arrays and dictionaries since the types are not stored
+type SQLValue struct {
+	Cadence cadence.Value
+	Format  SQLFormat
+	Options Options
+}
+
+// / Store a cadence value as JSON-CDC
+func NewSQLValue(value cadence.Value) SQLValue {
+	return SQLValue{Cadence: value, Format: SQLJsonCdc, Options: defaultOptions}
+}
+
+func (v SQLValue) Value() (driver.Value, error) {
+	if v.Cadence == nil {
+		return nil, nil
+	}
+
+	switch v.Format {
+	case SQLJsonCdc:
+		encoded, err := jsoncdc.Encode(v.Cadence)
+		if err != nil {
+			return nil, err
+		}
+		return string(bytes.TrimSpace(encoded)), nil
+	case SQLTerseJson:
+		encoded, err := json.Marshal(CadenceValueToInterfaceWithOption(v.Cadence, v.Options))
+		if err != nil {
+			return nil, err
+		}
+		return string(encoded), nil
+	}
+	return nil, fmt.Errorf("unknown sql format %d", v.Format)
+}
+
+func (v *SQLValue) Scan(src interface{}) error {
+	data, ok, err := sqlBytes(src)
+	if err != nil || !ok {
+		v.Cadence = nil
+		return err
+	}
+
+	switch v.Format {
+	case SQLJsonCdc:
+		value, err := jsoncdc.Decode(nil, data)
+		if err != nil {
+			return fmt.Errorf("cannot scan %s as JSON-CDC: %w", data, err)
+		}
+		v.Cadence = value
+		return nil
+	case SQLTerseJson:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var terse interface{}
+		if err := decoder.Decode(&terse); err != nil {
+			return fmt.Errorf("cannot scan %s as json: %w", data, err)
+		}
+		value, err := InputToCadenceWithOption(terse, nil, v.Options)
+		if err != nil {
+			return err
+		}
+		v.Cadence = value
+		return nil
+	}
+	return fmt.Errorf("unknown sql format %d", v.Format)
+}
+
+// / Addresses stored as a postgres array literal like {0x01cf0e2f2f715450,0xf8d6e0586b0a20c7}
+// /  It can be stored in a text[] column in postgres and a text column in other databases, json arrays are also read
+type SQLAddresses []string
+
+// / The addresses in a cadence value, sorted and without duplicates so they can be stored with SQLAddresses
+func SQLAddressesOf(value cadence.Value) SQLAddresses {
+	seen := map[string]bool{}
+	addresses := SQLAddresses{}
+	for _, address := range ExtractAddresses(value) {
+		if !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+func (a SQLAddresses) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return "{" + strings.Join(a, ",") + "}", nil
+}
+
+func (a *SQLAddresses) Scan(src interface{}) error {
+	data, ok, err := sqlBytes(src)
+	if err != nil || !ok {
+		*a = nil
+		return err
+	}
+
+	text := strings.TrimSpace(string(data))
+	if strings.HasPrefix(text, "[") {
+		var addresses []string
+		if err := json.Unmarshal(data, &addresses); err != nil {
+			return fmt.Errorf("cannot scan %s as addresses: %w", text, err)
+		}
+		*a = addresses
+		return nil
+	}
+
+	if !strings.HasPrefix(text, "{") || !strings.HasSuffix(text, "}") {
+		return fmt.Errorf("cannot scan %s as addresses, expected an array like {0x01cf0e2f2f715450}", text)
+	}
+	addresses := SQLAddresses{}
+	for _, address := range strings.Split(text[1:len(text)-1], ",") {
+		address = strings.Trim(strings.TrimSpace(address), `"`)
+		if address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	*a = addresses
+	return nil
+}
+
+// the bytes of a text or blob column, ok is false for NULL
+func sqlBytes(src interface{}) ([]byte, bool, error) {
+	switch src := src.(type) {
+	case nil:
+		return nil, false, nil
+	case []byte:
+		return src, true, nil
+	case string:
+		return []byte(src), true, nil
+	}
+	return nil, false, fmt.Errorf("cannot scan %T into a cadence value, expected text or bytes", src)
+}
@@ -0,0 +1,161 @@
+package underflow
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// a database driver that keeps the rows that are inserted in memory, every query returns all of them
+type memoryDriver struct {
+	mu   sync.Mutex
+	rows [][]driver.Value
+}
+
+func (d *memoryDriver) Open(string) (driver.Conn, error) { return &memoryConn{driver: d}, nil }
+
+type memoryConn struct{ driver *memoryDriver }
+
+func (c *memoryConn) Prepare(query string) (driver.Stmt, error) {
+	return &memoryStmt{conn: c, query: query}, nil
+}
+func (c *memoryConn) Close() error              { return nil }
+func (c *memoryConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type memoryStmt struct {
+	conn  *memoryConn
+	query string
+}
+
+func (s *memoryStmt) Close() error  { return nil }
+func (s *memoryStmt) NumInput() int { return -1 }
+func (s *memoryStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.conn.driver.mu.Lock()
+	defer s.conn.driver.mu.Unlock()
+	s.conn.driver.rows = append(s.conn.driver.rows, args)
+	return driver.RowsAffected(1), nil
+}
+func (s *memoryStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.conn.driver.mu.Lock()
+	defer s.conn.driver.mu.Unlock()
+	return &memoryRows{rows: append([][]driver.Value{}, s.conn.driver.rows...)}, nil
+}
+
+type memoryRows struct{ rows [][]driver.Value }
+
+func (r *memoryRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+func (r *memoryRows) Close() error { return nil }
+func (r *memoryRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+var registerMemoryDriver sync.Once
+
+func openMemoryDatabase(t *testing.T) *sql.DB {
+	registerMemoryDriver.Do(func() {
+		sql.Register("underflow-memory", &memoryDriver{})
+	})
+	db, err := sql.Open("underflow-memory", "")
+	require.NoError(t, err)
+	return db
+}
+
+func TestSQLValue(t *testing.T) {
+	event := cadence.NewStruct([]cadence.Value{
+		cadence.UFix64(150_000_000),
+		cadence.BytesToAddress([]byte{0x1}),
+		cadence.NewOptional(nil),
+	}).WithType(&cadence.StructType{
+		QualifiedIdentifier: "A.f8d6e0586b0a20c7.Debug.Deposit",
+		Fields: []cadence.Field{
+			{Identifier: "amount", Type: cadence.UFix64Type{}},
+			{Identifier: "to", Type: cadence.AddressType{}},
+			{Identifier: "from", Type: cadence.NewOptionalType(cadence.AddressType{})},
+		},
+	})
+
+	db := openMemoryDatabase(t)
+	_, err := db.Exec("INSERT", NewSQLValue(event), SQLValue{Cadence: event, Format: SQLTerseJson}, SQLValue{}, SQLAddressesOf(event))
+	require.NoError(t, err)
+
+	rows, err := db.Query("SELECT")
+	require.NoError(t, err)
+	defer rows.Close()
+	require.True(t, rows.Next())
+
+	var lossless SQLValue
+	terse := SQLValue{Format: SQLTerseJson}
+	var empty SQLValue
+	var addresses SQLAddresses
+	require.NoError(t, rows.Scan(&lossless, &terse, &empty, &addresses))
+
+	assert.Equal(t, event.String(), lossless.Cadence.String())
+	assert.Equal(t, "A.f8d6e0586b0a20c7.Debug.Deposit", lossless.Cadence.Type().ID())
+	assert.Equal(t, `{"amount": 1.50000000, "to": "0x0000000000000001"}`, terse.Cadence.String())
+	assert.Nil(t, empty.Cadence)
+	assert.Equal(t, SQLAddresses{"0x0000000000000001"}, addresses)
+}
+
+func TestSQLValueFormats(t *testing.T) {
+	stored, err := SQLValue{Cadence: cadence.String("foo")}.Value()
+	require.NoError(t, err)
+	assert.Equal(t, `{"value":"foo","type":"String"}`, stored)
+
+	stored, err = SQLValue{Cadence: cadence.String("foo"), Format: SQLTerseJson}.Value()
+	require.NoError(t, err)
+	assert.Equal(t, `"foo"`, stored)
+
+	fix64, err := cadence.NewFix64("-1.5")
+	require.NoError(t, err)
+	change := cadence.NewStruct([]cadence.Value{fix64}).WithType(&cadence.StructType{
+		QualifiedIdentifier: "A.f8d6e0586b0a20c7.Debug.Change",
+		Fields:              []cadence.Field{{Identifier: "delta", Type: cadence.Fix64Type{}}},
+	})
+	stored, err = SQLValue{Cadence: change, Format: SQLTerseJson}.Value()
+	require.NoError(t, err)
+	assert.Equal(t, `{"delta":-1.5}`, stored)
+	terse := SQLValue{Format: SQLTerseJson}
+	require.NoError(t, terse.Scan(stored))
+	assert.Equal(t, `{"delta": -1.50000000}`, terse.Cadence.String())
+
+	var value SQLValue
+	assert.ErrorContains(t, value.Scan(42), "expected text or bytes")
+	assert.ErrorContains(t, value.Scan(`{"foo":"bar"}`), "cannot scan")
+}
+
+func TestSQLAddressesScan(t *testing.T) {
+	tests := map[string]SQLAddresses{
+		`{0x01cf0e2f2f715450,0xf8d6e0586b0a20c7}`: {"0x01cf0e2f2f715450", "0xf8d6e0586b0a20c7"},
+		`{}`:                     {},
+		`["0x01cf0e2f2f715450"]`: {"0x01cf0e2f2f715450"},
+		`{"0x01cf0e2f2f715450", "0xf8d6e0586b0a20c7"}`: {"0x01cf0e2f2f715450", "0xf8d6e0586b0a20c7"},
+	}
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			var addresses SQLAddresses
+			require.NoError(t, addresses.Scan([]byte(input)))
+			assert.Equal(t, want, addresses)
+		})
+	}
+
+	var addresses SQLAddresses
+	assert.ErrorContains(t, addresses.Scan("0x1"), "expected an array")
+	require.NoError(t, addresses.Scan(nil))
+	assert.Nil(t, addresses)
+}
@@ -0,0 +1,126 @@
+package underflow
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/fatih/structtag"
+	"github.com/onflow/cadence"
+)
+
+var (
+	bigIntType     = reflect.TypeOf(big.Int{})
+	bigIntPtrType  = reflect.TypeOf(&big.Int{})
+	timeType       = reflect.TypeOf(time.Time{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+	jsonNumberType = reflect.TypeOf(json.Number(""))
+)
+
+var nanosecondsPerSecond = big.NewInt(int64(time.Second))
+
+// / Convert go standard library types that do not map to cadence by their kind, ok is false if the value is not such a type
+//
+//	big.Int is Int or the integer type in the tag, time.Time is UFix64 seconds since the unix epoch, time.Duration is UFix64 seconds,
+//	[]byte is [UInt8] or a hex String with the String tag and json.Number is Int or UFix64 depending on if it has decimals, Fix64 if it is a negative decimal
+func standardTypeToCadence(value reflect.Value, opt Options, tag *structtag.Tag) (result cadence.Value, ok bool, err error) {
+	inputType := value.Type()
+	switch inputType {
+	case bigIntPtrType:
+		if value.IsNil() {
+			return nil, true, fmt.Errorf("cannot convert a nil *big.Int")
+		}
+		result, err := bigIntToCadence(value.Interface().(*big.Int), tag)
+		return result, true, err
+	case bigIntType:
+		i := value.Interface().(big.Int)
+		result, err := bigIntToCadence(&i, tag)
+		return result, true, err
+	case timeType:
+		t := value.Interface().(time.Time)
+		nanos := new(big.Int).Mul(big.NewInt(t.Unix()), nanosecondsPerSecond)
+		nanos.Add(nanos, big.NewInt(int64(t.Nanosecond())))
+		result, err := ratToFixedPoint(new(big.Rat).SetFrac(nanos, nanosecondsPerSecond), tag, opt)
+		return result, true, err
+	case durationType:
+		result, err := ratToFixedPoint(new(big.Rat).SetFrac(big.NewInt(value.Int()), nanosecondsPerSecond), tag, opt)
+		return result, true, err
+	case jsonNumberType:
+		result, err := jsonNumberToCadence(json.Number(value.String()), opt, tag)
+		return result, true, err
+	}
+
+	switch inputType.Kind() {
+	case reflect.Slice:
+		if inputType.Elem().Kind() == reflect.Uint8 && tagHasOption(tag, "String") {
+			result, err := cadence.NewString(hex.EncodeToString(value.Bytes()))
+			return result, true, err
+		}
+	case reflect.Complex64, reflect.Complex128:
+		return nil, true, fmt.Errorf("cannot convert complex number %v, cadence has no complex numbers", value.Complex())
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer, reflect.Uintptr:
+		return nil, true, fmt.Errorf("cannot convert a value of type %s to cadence", inputType)
+	}
+	return nil, false, nil
+}
+
+// the integer types a big.Int can be converted into with a tag option
+var bigIntConverters = map[string]func(*big.Int) (cadence.Value, error){
+	"Int": func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewIntFromBig(i), nil
+	},
+	"Int128": func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewInt128FromBig(i)
+	},
+	"Int256": func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewInt256FromBig(i)
+	},
+	"UInt": func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewUIntFromBig(i)
+	},
+	"UInt128": func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewUInt128FromBig(i)
+	},
+	"UInt256": func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewUInt256FromBig(i)
+	},
+}
+
+func bigIntToCadence(i *big.Int, tag *structtag.Tag) (cadence.Value, error) {
+	name := "Int"
+	if tag != nil {
+		for _, option := range tag.Options {
+			if _, ok := bigIntConverters[option]; ok {
+				name = option
+			}
+		}
+	}
+
+	result, err := bigIntConverters[name](new(big.Int).Set(i))
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert %s to %s: %w", i, name, err)
+	}
+	return result, nil
+}
+
+func ratToFixedPoint(rat *big.Rat, tag *structtag.Tag, opt Options) (cadence.Value, error) {
+	return decimalToFixedPoint(rat.FloatString(9), tagHasOption(tag, "Fix64"), opt.FloatRounding)
+}
+
+func jsonNumberToCadence(number json.Number, opt Options, tag *structtag.Tag) (cadence.Value, error) {
+	if strings.ContainsAny(number.String(), ".eE") {
+		// like cadence literals negative decimals are Fix64, UFix64 cannot hold them
+		fix64 := tagHasOption(tag, "Fix64") || strings.HasPrefix(number.String(), "-")
+		return decimalToFixedPoint(number.String(), fix64, opt.FloatRounding)
+	}
+
+	i, ok := new(big.Int).SetString(number.String(), 10)
+	if !ok {
+		return nil, fmt.Errorf("cannot convert json number %q, it is not a number", number.String())
+	}
+	return bigIntToCadence(i, tag)
+}
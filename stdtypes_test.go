@@ -0,0 +1,109 @@
+package underflow
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Debug_Standard struct {
+	Supply    *big.Int      `cadence:"supply,UInt256"`
+	Balance   big.Int       `cadence:"balance"`
+	Created   time.Time     `cadence:"created"`
+	Timeout   time.Duration `cadence:"timeout"`
+	Key       []byte        `cadence:"key,String"`
+	Signature []byte        `cadence:"signature"`
+	Amount    json.Number   `cadence:"amount"`
+	Count     json.Number   `cadence:"count,UInt"`
+}
+
+func TestStandardTypesInputToCadence(t *testing.T) {
+	supply, _ := new(big.Int).SetString("1000000000000000000000000", 10)
+	input := Debug_Standard{
+		Supply:    supply,
+		Balance:   *big.NewInt(-42),
+		Created:   time.Date(2024, 2, 25, 12, 0, 0, 123456789, time.UTC),
+		Timeout:   1500 * time.Millisecond,
+		Key:       []byte{0xde, 0xad},
+		Signature: []byte{1, 2},
+		Amount:    json.Number("12.5"),
+		Count:     json.Number("7"),
+	}
+
+	value, err := InputToCadence(input, func(string) (string, error) {
+		return "A.f8d6e0586b0a20c7.Debug.Standard", nil
+	})
+	require.NoError(t, err)
+
+	fields := value.(cadence.Struct).Fields
+	assert.Equal(t, []string{
+		"1000000000000000000000000",
+		"-42",
+		"1708862400.12345679",
+		"1.50000000",
+		`"dead"`,
+		"[1, 2]",
+		"12.50000000",
+		"7",
+	}, cadenceStrings(fields))
+
+	types := []string{}
+	for _, field := range value.(cadence.Struct).StructType.Fields {
+		types = append(types, field.Type.ID())
+	}
+	// the type of a json.Number depends on the number, so the field is AnyStruct like in CadenceTypeOf
+	assert.Equal(t, []string{"UInt256", "Int", "UFix64", "UFix64", "String", "[UInt8]", "AnyStruct", "AnyStruct"}, types)
+}
+
+func TestStandardTypesErrors(t *testing.T) {
+	type Debug_Negative struct {
+		Supply *big.Int `cadence:"supply,UInt128"`
+	}
+	resolver := func(string) (string, error) {
+		return "A.f8d6e0586b0a20c7.Debug.Negative", nil
+	}
+
+	_, err := InputToCadence(Debug_Negative{Supply: big.NewInt(-1)}, resolver)
+	assert.ErrorContains(t, err, "cannot convert -1 to UInt128")
+
+	_, err = InputToCadence(Debug_Negative{}, resolver)
+	assert.ErrorContains(t, err, "cannot convert a nil *big.Int")
+
+	_, err = InputToCadence(complex(1, 2), nil)
+	assert.ErrorContains(t, err, "cadence has no complex numbers")
+
+	_, err = InputToCadence(func() {}, nil)
+	assert.ErrorContains(t, err, "cannot convert a value of type func()")
+
+	_, err = InputToCadence(time.Unix(-1, 0), nil)
+	assert.ErrorContains(t, err, "use Fix64 instead")
+}
+
+func TestStandardTypesNegativeJsonNumber(t *testing.T) {
+	tests := map[string]string{
+		"-1.5": "Fix64",
+		"1.5":  "UFix64",
+		"-15":  "Int",
+		"-1e2": "Fix64",
+	}
+	for number, want := range tests {
+		t.Run(number, func(t *testing.T) {
+			value, err := InputToCadence(json.Number(number), nil)
+			require.NoError(t, err)
+			assert.Equal(t, want, value.Type().ID())
+		})
+	}
+}
+
+func cadenceStrings(values []cadence.Value) []string {
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = value.String()
+	}
+	return result
+}
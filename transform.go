@@ -0,0 +1,160 @@
+package underflow
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence"
+)
+
+// TransformFunc returns the value that should replace value at path, return value unchanged to keep it
+type TransformFunc func(path ValuePath, value cadence.Value) (cadence.Value, error)
+
+// / Transform rebuilds a cadence value calling fn for every value in it, it is the write side companion to Walk
+// /  The value is rebuilt bottom up so fn gets values where the children are already transformed.
+// /  The input is never modified, struct, resource, event and container types are kept and optionals stay wrapped
+func Transform(value cadence.Value, fn TransformFunc) (cadence.Value, error) {
+	return transform(nil, value, fn)
+}
+
+func transform(path ValuePath, value cadence.Value, fn TransformFunc) (cadence.Value, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	rebuilt, err := transformChildren(path, value, fn)
+	if err != nil {
+		return nil, err
+	}
+	return fn(path, rebuilt)
+}
+
+func transformChildren(path ValuePath, value cadence.Value, fn TransformFunc) (cadence.Value, error) {
+	child := func(element PathElement) ValuePath {
+		return append(path[:len(path):len(path)], element)
+	}
+
+	switch value := value.(type) {
+	case cadence.Optional:
+		inner, err := transform(path, value.Value, fn)
+		if err != nil {
+			return nil, err
+		}
+		value.Value = inner
+		return value, nil
+	case cadence.Array:
+		values, err := transformValues(value.Values, func(i int) ValuePath {
+			return child(PathElement{Kind: PathIndex, Index: i})
+		}, fn)
+		if err != nil {
+			return nil, err
+		}
+		value.Values = values
+		return value, nil
+	case cadence.Dictionary:
+		pairs := make([]cadence.KeyValuePair, len(value.Pairs))
+		for i, pair := range value.Pairs {
+			key, err := transform(child(PathElement{Kind: PathKey, Key: pair.Key}), pair.Key, fn)
+			if err != nil {
+				return nil, err
+			}
+			val, err := transform(child(PathElement{Kind: PathValue, Key: pair.Key}), pair.Value, fn)
+			if err != nil {
+				return nil, err
+			}
+			pairs[i] = cadence.KeyValuePair{Key: key, Value: val}
+		}
+		value.Pairs = pairs
+		return value, nil
+	case cadence.PathCapability:
+		address, err := transformField[cadence.Address](child(PathElement{Kind: PathField, Field: "address"}), value.Address, fn)
+		if err != nil {
+			return nil, err
+		}
+		capabilityPath, err := transformField[cadence.Path](child(PathElement{Kind: PathField, Field: "path"}), value.Path, fn)
+		if err != nil {
+			return nil, err
+		}
+		value.Address = address
+		value.Path = capabilityPath
+		return value, nil
+	case cadence.IDCapability:
+		address, err := transformField[cadence.Address](child(PathElement{Kind: PathField, Field: "address"}), value.Address, fn)
+		if err != nil {
+			return nil, err
+		}
+		id, err := transformField[cadence.UInt64](child(PathElement{Kind: PathField, Field: "id"}), value.ID, fn)
+		if err != nil {
+			return nil, err
+		}
+		value.Address = address
+		value.ID = id
+		return value, nil
+	case cadence.PathLink:
+		target, err := transformField[cadence.Path](child(PathElement{Kind: PathField, Field: "targetPath"}), value.TargetPath, fn)
+		if err != nil {
+			return nil, err
+		}
+		value.TargetPath = target
+		return value, nil
+	}
+
+	values, fields, ok := compositeFields(value)
+	if !ok {
+		return value, nil
+	}
+
+	transformed, err := transformValues(values, func(i int) ValuePath {
+		return child(PathElement{Kind: PathField, Field: compositeFieldName(fields, i)})
+	}, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch value := value.(type) {
+	case cadence.Struct:
+		value.Fields = transformed
+		return value, nil
+	case cadence.Resource:
+		value.Fields = transformed
+		return value, nil
+	case cadence.Event:
+		value.Fields = transformed
+		return value, nil
+	case cadence.Enum:
+		value.Fields = transformed
+		return value, nil
+	case cadence.Contract:
+		value.Fields = transformed
+		return value, nil
+	case cadence.Attachment:
+		value.Fields = transformed
+		return value, nil
+	}
+	return value, nil
+}
+
+func transformValues(values []cadence.Value, path func(int) ValuePath, fn TransformFunc) ([]cadence.Value, error) {
+	result := make([]cadence.Value, len(values))
+	for i, item := range values {
+		transformed, err := transform(path(i), item, fn)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = transformed
+	}
+	return result, nil
+}
+
+// transform a field that has a fixed go type, like the address of a capability
+func transformField[T cadence.Value](path ValuePath, value T, fn TransformFunc) (T, error) {
+	var empty T
+	transformed, err := transform(path, value, fn)
+	if err != nil {
+		return empty, err
+	}
+	result, ok := transformed.(T)
+	if !ok {
+		return empty, fmt.Errorf("cannot transform %s, expected %T but got %T", path, empty, transformed)
+	}
+	return result, nil
+}
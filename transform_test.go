@@ -0,0 +1,81 @@
+package underflow
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransformReplaceAddresses(t *testing.T) {
+	emulator := cadence.NewAddress([8]byte{0, 0, 0, 0, 0, 0, 0, 1})
+	testnet := cadence.NewAddress([8]byte{0, 0, 0, 0, 0, 0, 0, 2})
+
+	input := walkTestValue()
+	result, err := Transform(input, func(path ValuePath, value cadence.Value) (cadence.Value, error) {
+		if value == emulator {
+			return testnet, nil
+		}
+		return value, nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"0x0000000000000001", "0x0000000000000002"}, ExtractAddresses(input), "input is not modified")
+	assert.Equal(t, []string{"0x0000000000000002", "0x0000000000000002"}, ExtractAddresses(result))
+
+	optional, ok := result.(cadence.Optional)
+	require.True(t, ok, "optional wrapping is kept")
+	strct := optional.Value.(cadence.Array).Values[0].(cadence.Struct)
+	assert.Equal(t, "Market.Offer", strct.StructType.ID())
+}
+
+func TestTransformRedactAndNormalise(t *testing.T) {
+	eventType := &cadence.EventType{
+		Location:            common.StringLocation("test"),
+		QualifiedIdentifier: "Login",
+		Fields: []cadence.Field{
+			{Identifier: "user", Type: cadence.StringType{}},
+			{Identifier: "password", Type: cadence.StringType{}},
+		},
+	}
+	event := cadence.NewEvent([]cadence.Value{cadenceString(" Bjartek "), cadenceString("hunter2")}).WithType(eventType)
+
+	result, err := Transform(event, func(path ValuePath, value cadence.Value) (cadence.Value, error) {
+		if path.String() == ".password" {
+			return cadenceString("***"), nil
+		}
+		if str, ok := value.(cadence.String); ok {
+			return cadenceString(strings.ToLower(strings.TrimSpace(string(str)))), nil
+		}
+		return value, nil
+	})
+	require.NoError(t, err)
+
+	transformed := result.(cadence.Event)
+	assert.Same(t, eventType, transformed.EventType)
+	assert.Equal(t, map[string]interface{}{"user": "bjartek", "password": "***"}, CadenceValueToInterface(result))
+}
+
+func TestTransformErrors(t *testing.T) {
+	t.Run("error from function", func(t *testing.T) {
+		fail := errors.New("fail")
+		_, err := Transform(walkTestValue(), func(path ValuePath, value cadence.Value) (cadence.Value, error) {
+			return nil, fail
+		})
+		assert.ErrorIs(t, err, fail)
+	})
+
+	t.Run("wrong type for capability address", func(t *testing.T) {
+		_, err := Transform(walkTestValue(), func(path ValuePath, value cadence.Value) (cadence.Value, error) {
+			if _, ok := value.(cadence.Address); ok {
+				return cadenceString("foo"), nil
+			}
+			return value, nil
+		})
+		assert.ErrorContains(t, err, "cannot transform [1].address, expected cadence.Address but got cadence.String")
+	})
+}
@@ -0,0 +1,221 @@
+package underflow
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/fatih/structtag"
+	"github.com/onflow/cadence"
+)
+
+// / The cadence type InputToCadence creates for values of a go type, struct names are resolved with the resolver and struct tags are used the same way
+// /  Types whose cadence type depends on the value, like json.Number and interfaces, are AnyStruct, also in the struct types of converted values
+func CadenceTypeOf(inputType reflect.Type, resolver InputResolver) (cadence.Type, error) {
+	return cadenceTypeOf(inputType, memoizeResolver(resolver), nil)
+}
+
+// the tag is the cadence struct tag of the field the type is in
+func cadenceTypeOf(inputType reflect.Type, resolver InputResolver, tag *structtag.Tag) (cadence.Type, error) {
+	key := staticTypeKeyFor(inputType, tag)
+	if cached, ok := staticTypes.Load(key); ok && cached.(staticType).resolvesTo(resolver) {
+		return cached.(staticType).typ, nil
+	}
+
+	builder := &typeBuilder{resolver: resolver, structs: map[reflect.Type]*cadence.StructType{}}
+	typ, err := builder.typeOf(inputType, tag)
+	if err != nil {
+		return nil, err
+	}
+	structs := make(map[reflect.Type]string, len(builder.structs))
+	for structInputType, structType := range builder.structs {
+		structs[structInputType] = structType.QualifiedIdentifier
+	}
+	// cadence types compute their id lazily, do it before the type is shared between goroutines
+	computeTypeIDs(typ, map[*cadence.StructType]bool{})
+	staticTypes.Store(key, staticType{typ: typ, structs: structs})
+	return typ, nil
+}
+
+// a cached type and the identifiers its structs were resolved to
+type staticType struct {
+	typ     cadence.Type
+	structs map[reflect.Type]string
+}
+
+// a type with structs is only reused if the resolver resolves them the same way, like for the same network
+func (t staticType) resolvesTo(resolver InputResolver) bool {
+	for inputType, identifier := range t.structs {
+		resolved, err := resolveStructName(resolver, inputType)
+		if err != nil || resolved != identifier {
+			return false
+		}
+	}
+	return true
+}
+
+func computeTypeIDs(typ cadence.Type, seen map[*cadence.StructType]bool) {
+	switch typ := typ.(type) {
+	case *cadence.StructType:
+		if seen[typ] {
+			return
+		}
+		seen[typ] = true
+		for _, field := range typ.Fields {
+			computeTypeIDs(field.Type, seen)
+		}
+	case *cadence.OptionalType:
+		computeTypeIDs(typ.Type, seen)
+	case *cadence.VariableSizedArrayType:
+		computeTypeIDs(typ.ElementType, seen)
+	case *cadence.ConstantSizedArrayType:
+		computeTypeIDs(typ.ElementType, seen)
+	case *cadence.DictionaryType:
+		computeTypeIDs(typ.KeyType, seen)
+		computeTypeIDs(typ.ElementType, seen)
+	}
+	typ.ID()
+}
+
+// builds cadence types for go types, structs are remembered so recursive structs refer to the same type
+type typeBuilder struct {
+	resolver InputResolver
+	structs  map[reflect.Type]*cadence.StructType
+}
+
+func (b *typeBuilder) typeOf(inputType reflect.Type, tag *structtag.Tag) (cadence.Type, error) {
+	if implementsMarshaler(inputType) || (inputType.Kind() != reflect.Interface && inputType.Implements(cadenceValueType)) {
+		return cadence.AnyStructType{}, nil
+	}
+
+	switch inputType {
+	case addressType:
+		return cadence.AddressType{}, nil
+	case bigIntType, bigIntPtrType:
+		name := "Int"
+		if tag != nil {
+			for _, option := range tag.Options {
+				if _, ok := bigIntConverters[option]; ok {
+					name = option
+				}
+			}
+		}
+		return namedTypes[name], nil
+	case timeType, durationType:
+		return fixedPointType(tag), nil
+	case jsonNumberType:
+		return cadence.AnyStructType{}, nil
+	}
+
+	switch inputType.Kind() {
+	case reflect.Interface:
+		return cadence.AnyStructType{}, nil
+	case reflect.Struct:
+		return b.structType(inputType)
+	case reflect.Pointer:
+		elementType, err := b.typeOf(inputType.Elem(), tag)
+		if err != nil {
+			return nil, err
+		}
+		return cadence.NewOptionalType(elementType), nil
+	case reflect.Int:
+		return cadence.IntType{}, nil
+	case reflect.Int8:
+		return cadence.Int8Type{}, nil
+	case reflect.Int16:
+		return cadence.Int16Type{}, nil
+	case reflect.Int32:
+		return cadence.Int32Type{}, nil
+	case reflect.Int64:
+		return cadence.Int64Type{}, nil
+	case reflect.Bool:
+		return cadence.BoolType{}, nil
+	case reflect.Uint:
+		return cadence.UIntType{}, nil
+	case reflect.Uint8:
+		return cadence.UInt8Type{}, nil
+	case reflect.Uint16:
+		return cadence.UInt16Type{}, nil
+	case reflect.Uint32:
+		return cadence.UInt32Type{}, nil
+	case reflect.Uint64:
+		return cadence.UInt64Type{}, nil
+	case reflect.String:
+		return cadence.StringType{}, nil
+	case reflect.Float32, reflect.Float64:
+		return fixedPointType(tag), nil
+	case reflect.Map:
+		keyType, err := b.typeOf(inputType.Key(), nil)
+		if err != nil {
+			return nil, err
+		}
+		elementType, err := b.typeOf(inputType.Elem(), tag)
+		if err != nil {
+			return nil, err
+		}
+		return cadence.NewDictionaryType(keyType, elementType), nil
+	case reflect.Slice:
+		if inputType.Elem().Kind() == reflect.Uint8 && tagHasOption(tag, "String") {
+			return cadence.StringType{}, nil
+		}
+		elementType, err := b.typeOf(inputType.Elem(), tag)
+		if err != nil {
+			return nil, err
+		}
+		return cadence.NewVariableSizedArrayType(elementType), nil
+	case reflect.Array:
+		elementType, err := b.typeOf(inputType.Elem(), tag)
+		if err != nil {
+			return nil, err
+		}
+		return cadence.NewConstantSizedArrayType(uint(inputType.Len()), elementType), nil
+	}
+
+	return nil, fmt.Errorf("cannot convert a value of type %s to cadence", inputType)
+}
+
+func fixedPointType(tag *structtag.Tag) cadence.Type {
+	if tagHasOption(tag, "Fix64") {
+		return cadence.Fix64Type{}
+	}
+	return cadence.UFix64Type{}
+}
+
+func (b *typeBuilder) structType(inputType reflect.Type) (cadence.Type, error) {
+	if structType, ok := b.structs[inputType]; ok {
+		return structType, nil
+	}
+
+	resolvedIdentifier, err := resolveStructName(b.resolver, inputType)
+	if err != nil {
+		return nil, err
+	}
+	structType := &cadence.StructType{QualifiedIdentifier: resolvedIdentifier}
+	b.structs[inputType] = structType
+
+	plan, err := structPlanFor(inputType)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]cadence.Field, 0, len(plan.fields))
+	for _, field := range plan.fields {
+		fieldType, tag := field.explicitType, field.tag
+		if fieldType == nil && IsTagCadecenAddress(tag) {
+			fieldType = cadence.AddressType{}
+		}
+		if fieldType == nil {
+			fieldType, err = b.typeOf(field.field.Type, tag)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		fields = append(fields, cadence.Field{
+			Identifier: field.name,
+			Type:       fieldType,
+		})
+	}
+
+	structType.Fields = fields
+	return structType, nil
+}
@@ -0,0 +1,161 @@
+package underflow
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/ccf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticallyTypedContainers(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "empty slice", value: []string{}, want: "[String]"},
+		{name: "empty map", value: map[string]int{}, want: "{String:Int}"},
+		{name: "fixed array", value: [2]uint8{1, 2}, want: "[UInt8;2]"},
+		{name: "nested", value: map[string][]*int64{}, want: "{String:[Int64?]}"},
+		{name: "interface elements", value: []interface{}{}, want: "[AnyStruct]"},
+		{name: "addresses", value: []Address{}, want: "[Address]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := InputToCadence(test.value, nil)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, value.Type().ID())
+		})
+	}
+}
+
+func TestStaticallyTypedStructElements(t *testing.T) {
+	type Debug_Node struct {
+		Name     string        `cadence:"name"`
+		Children []*Debug_Node `cadence:"children"`
+		Prices   []float64     `cadence:"prices,Fix64"`
+	}
+
+	value, err := InputToCadence(Debug_Node{Name: "root", Children: []*Debug_Node{}, Prices: []float64{}}, func(string) (string, error) {
+		return "A.f8d6e0586b0a20c7.Debug.Node", nil
+	})
+	require.NoError(t, err)
+
+	fields := value.(cadence.Struct).Fields
+	childType := fields[1].Type().(*cadence.VariableSizedArrayType)
+	assert.Equal(t, "A.f8d6e0586b0a20c7.Debug.Node?", childType.ElementType.ID())
+	structType := childType.ElementType.(*cadence.OptionalType).Type.(*cadence.StructType)
+	assert.Equal(t, "[A.f8d6e0586b0a20c7.Debug.Node?]", structType.Fields[1].Type.ID())
+	assert.Equal(t, "[Fix64]", fields[2].Type().ID())
+}
+
+func TestStaticallyTypedCcf(t *testing.T) {
+	value, err := InputToCadence(map[string]int{}, nil)
+	require.NoError(t, err)
+
+	encoded, err := ccf.Encode(value)
+	require.NoError(t, err)
+
+	decoded, err := ccf.Decode(nil, encoded)
+	require.NoError(t, err)
+	assert.Equal(t, "{String:Int}", decoded.Type().ID())
+}
+
+type Debug_Schema struct {
+	ID       uint64            `cadence:"id"`
+	Owner    string            `cadence:"owner,cadenceAddress"`
+	Nickname *string           `cadence:"nickname"`
+	Tags     map[string]string `cadence:"tags"`
+	Amount   int               `cadence:"amount,type=UFix64"`
+	Internal string            `cadence:"-"`
+}
+
+func TestCadenceTypeOf(t *testing.T) {
+	resolver := func(name string) (string, error) {
+		return "A.f8d6e0586b0a20c7.Debug." + name[len("Debug_"):], nil
+	}
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "int", value: 1, want: "Int"},
+		{name: "float", value: 1.5, want: "UFix64"},
+		{name: "pointer", value: new(string), want: "String?"},
+		{name: "slice of pointers", value: []*uint8{}, want: "[UInt8?]"},
+		{name: "address", value: Address{}, want: "Address"},
+		{name: "struct", value: Debug_Schema{}, want: "A.f8d6e0586b0a20c7.Debug.Schema"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			typ, err := CadenceTypeOf(reflect.TypeOf(test.value), resolver)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, typ.ID())
+		})
+	}
+
+	typ, err := CadenceTypeOf(reflect.TypeOf(Debug_Schema{}), resolver)
+	require.NoError(t, err)
+	fields := []string{}
+	for _, field := range typ.(*cadence.StructType).Fields {
+		fields = append(fields, field.Identifier+": "+field.Type.ID())
+	}
+	assert.Equal(t, []string{"id: UInt64", "owner: Address", "nickname: String?", "tags: {String:String}", "amount: UFix64"}, fields)
+
+	value, err := InputToCadence(Debug_Schema{Owner: "0x1"}, resolver)
+	require.NoError(t, err)
+	assert.Equal(t, "String?", value.(cadence.Struct).StructType.Fields[2].Type.ID())
+
+	_, err = CadenceTypeOf(reflect.TypeOf(make(chan int)), resolver)
+	assert.ErrorContains(t, err, "cannot convert a value of type chan int to cadence")
+
+	_, err = CadenceTypeOf(reflect.TypeOf(Debug_Schema{}), nil)
+	assert.ErrorContains(t, err, "cannot convert underflow.Debug_Schema to cadence without a resolver")
+}
+
+func TestCadenceTypeOfInterfaceFields(t *testing.T) {
+	type Debug_Any struct {
+		Value interface{} `cadence:"value"`
+	}
+	resolver := func(string) (string, error) {
+		return "A.f8d6e0586b0a20c7.Debug.Any", nil
+	}
+
+	value, err := InputToCadence([]Debug_Any{{Value: "foo"}, {Value: 1}}, resolver)
+	require.NoError(t, err)
+	array := value.(cadence.Array)
+	elementType := array.ArrayType.Element().(*cadence.StructType)
+	assert.Equal(t, "AnyStruct", elementType.Fields[0].Type.ID())
+	for _, element := range array.Values {
+		assert.Equal(t, elementType.Fields, element.(cadence.Struct).StructType.Fields)
+	}
+}
+
+func TestCadenceTypeOfIsCachedPerResolver(t *testing.T) {
+	type Debug_Cached struct {
+		Children []Debug_Cached `cadence:"children"`
+	}
+	emulator := func(string) (string, error) {
+		return "A.f8d6e0586b0a20c7.Debug.Cached", nil
+	}
+	testnet := func(string) (string, error) {
+		return "A.0000000000000001.Debug.Cached", nil
+	}
+
+	first, err := CadenceTypeOf(reflect.TypeOf([]Debug_Cached{}), emulator)
+	require.NoError(t, err)
+	second, err := CadenceTypeOf(reflect.TypeOf([]Debug_Cached{}), emulator)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	other, err := CadenceTypeOf(reflect.TypeOf([]Debug_Cached{}), testnet)
+	require.NoError(t, err)
+	assert.Equal(t, "[A.0000000000000001.Debug.Cached]", other.ID())
+	assert.Equal(t, "[A.f8d6e0586b0a20c7.Debug.Cached]", first.ID())
+}
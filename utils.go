@@ -1,7 +1,6 @@
 package underflow
 
 import (
-	"encoding/hex"
 	"strconv"
 	"strings"
 
@@ -31,14 +30,10 @@ func getAndUnquoteString(value cadence.Value) string {
 
 // HexToAddress converts a hex string to an Address.
 func hexToAddress(h string) (*cadence.Address, error) {
-	trimmed := strings.TrimPrefix(h, "0x")
-	if len(trimmed)%2 == 1 {
-		trimmed = "0" + trimmed
-	}
-	b, err := hex.DecodeString(trimmed)
+	address, err := ParseAddress(h)
 	if err != nil {
 		return nil, err
 	}
-	address := cadence.BytesToAddress(b)
-	return &address, nil
+	cadenceAddress := address.Cadence()
+	return &cadenceAddress, nil
 }
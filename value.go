@@ -0,0 +1,61 @@
+package underflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+)
+
+// / A cadence value that can be used in structs that are encoded with encoding/json
+// /  It is written as the terse json from CadenceValueToInterfaceWithOption with the Options and is always read back from JSON-CDC,
+// /  the terse json does not have the types so it cannot be read back. Set JSONCDC to write JSON-CDC so that json.Marshal and json.Unmarshal round trip
+type Value struct {
+	Value   cadence.Value
+	Options Options
+	// write the value as JSON-CDC instead of terse json, the Options are not used then
+	JSONCDC bool
+}
+
+// / Wrap a cadence value, it is written with the default options
+func NewValue(value cadence.Value) Value {
+	return Value{Value: value, Options: defaultOptions}
+}
+
+func (v Value) MarshalJSON() ([]byte, error) {
+	if v.Value == nil {
+		return []byte("null"), nil
+	}
+	if v.JSONCDC {
+		return jsoncdc.Encode(v.Value)
+	}
+	return json.Marshal(CadenceValueToInterfaceWithOption(v.Value, v.Options))
+}
+
+func (v *Value) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		v.Value = nil
+		return nil
+	}
+	value, err := jsoncdc.Decode(nil, data)
+	if err != nil {
+		return fmt.Errorf("cannot read %s as a cadence value, it must be JSON-CDC: %w", data, err)
+	}
+	v.Value = value
+	return nil
+}
+
+// the wrapped value is used when a Value is converted into cadence
+func (v Value) MarshalCadence(InputResolver) (cadence.Value, error) {
+	if v.Value == nil {
+		return cadence.NewOptional(nil), nil
+	}
+	return v.Value, nil
+}
+
+func (v *Value) UnmarshalCadence(value cadence.Value) error {
+	v.Value = value
+	return nil
+}
@@ -0,0 +1,80 @@
+package underflow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type apiResponse struct {
+	ID     string `json:"id"`
+	Result Value  `json:"result"`
+	Empty  *Value `json:"empty,omitempty"`
+}
+
+func TestValueMarshalJSON(t *testing.T) {
+	dictionary := cadence.NewDictionary([]cadence.KeyValuePair{
+		{Key: cadence.String("amount"), Value: cadence.UFix64(150_000_000)},
+		{Key: cadence.String("owner"), Value: cadence.BytesToAddress([]byte{0x1})},
+	})
+
+	result, err := json.Marshal(apiResponse{ID: "1", Result: NewValue(dictionary)})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"1","result":{"amount":1.5,"owner":"0x0000000000000001"}}`, string(result))
+
+	result, err = json.Marshal(apiResponse{ID: "2", Result: Value{Value: dictionary, Options: Options{UseStringForFixedNumbers: true}}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"2","result":{"amount":"1.50000000","owner":"0x0000000000000001"}}`, string(result))
+
+	result, err = json.Marshal(apiResponse{ID: "3"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"3","result":null}`, string(result))
+}
+
+func TestValueUnmarshalJSON(t *testing.T) {
+	var response apiResponse
+	err := json.Unmarshal([]byte(`{"id":"1","result":{"type":"UFix64","value":"1.50000000"},"empty":null}`), &response)
+	require.NoError(t, err)
+	assert.Equal(t, cadence.UFix64(150_000_000), response.Result.Value)
+	assert.Nil(t, response.Empty)
+
+	err = json.Unmarshal([]byte(`{"result":{"amount":1.5}}`), &response)
+	assert.ErrorContains(t, err, "it must be JSON-CDC")
+}
+
+func TestValueJSONCDCRoundTrip(t *testing.T) {
+	dictionary := cadence.NewDictionary([]cadence.KeyValuePair{
+		{Key: cadence.String("amount"), Value: cadence.UFix64(150_000_000)},
+	}).WithType(cadence.NewDictionaryType(cadence.StringType{}, cadence.UFix64Type{}))
+
+	result, err := json.Marshal(apiResponse{ID: "1", Result: Value{Value: dictionary, JSONCDC: true}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"1","result":{"type":"Dictionary","value":[{"key":{"type":"String","value":"amount"},"value":{"type":"UFix64","value":"1.50000000"}}]}}`, string(result))
+
+	var response apiResponse
+	require.NoError(t, json.Unmarshal(result, &response))
+	assert.Equal(t, dictionary.String(), response.Result.Value.String())
+}
+
+func TestValueInStructs(t *testing.T) {
+	type Debug_Wrapped struct {
+		Value Value `cadence:"value"`
+	}
+	resolver := func(string) (string, error) {
+		return "A.f8d6e0586b0a20c7.Debug.Wrapped", nil
+	}
+
+	value, err := InputToCadence(Debug_Wrapped{Value: NewValue(cadence.String("foo"))}, resolver)
+	require.NoError(t, err)
+	assert.Equal(t, `A.f8d6e0586b0a20c7.Debug.Wrapped(value: "foo")`, value.String())
+
+	var result Debug_Wrapped
+	require.NoError(t, CadenceToInput(value, &result))
+	assert.Equal(t, cadence.String("foo"), result.Value.Value)
+
+	_, ok := interface{}(NewValue(cadence.String("foo"))).(cadence.Value)
+	assert.False(t, ok, "a Value is not a cadence value so it is not wrapped twice")
+}
@@ -0,0 +1,243 @@
+package underflow
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence"
+)
+
+// PathElementKind is the kind of step taken from a value to one of its children
+type PathElementKind int
+
+const (
+	// a named field in a composite or a capability
+	PathField PathElementKind = iota
+	// an element in an array
+	PathIndex
+	// a key in a dictionary
+	PathKey
+	// a value in a dictionary
+	PathValue
+)
+
+// PathElement is a single step from a value to one of its children
+type PathElement struct {
+	Kind PathElementKind
+	// the name of the field for PathField
+	Field string
+	// the index in the array for PathIndex
+	Index int
+	// the dictionary key for PathKey and PathValue
+	Key cadence.Value
+}
+
+func (e PathElement) String() string {
+	switch e.Kind {
+	case PathIndex:
+		return fmt.Sprintf("[%d]", e.Index)
+	case PathKey:
+		return fmt.Sprintf("{%s}", e.Key.String())
+	case PathValue:
+		return fmt.Sprintf("[%s]", e.Key.String())
+	default:
+		return "." + e.Field
+	}
+}
+
+// ValuePath is the path from the root value given to Walk to the current value
+//
+//	optionals are transparent and do not add an element to the path
+type ValuePath []PathElement
+
+// / Render the path like .offers[2].buyer, dictionary values are rendered as [key] and dictionary keys as {key}
+func (p ValuePath) String() string {
+	var sb strings.Builder
+	for _, element := range p {
+		sb.WriteString(element.String())
+	}
+	return sb.String()
+}
+
+// / The last element in the path, ok is false for the root value
+func (p ValuePath) Last() (element PathElement, ok bool) {
+	if len(p) == 0 {
+		return PathElement{}, false
+	}
+	return p[len(p)-1], true
+}
+
+// Visitor is called by Walk when it enters and leaves every value
+type Visitor interface {
+	// Enter is called before the children of a value are walked, return SkipValue to skip the children
+	Enter(path ValuePath, value cadence.Value) error
+	// Leave is called after the children of a value are walked, also if Enter returned SkipValue
+	Leave(path ValuePath, value cadence.Value) error
+}
+
+// SkipValue can be returned from Visitor.Enter to skip walking the children of a value
+var SkipValue = errors.New("skip value")
+
+// VisitorFuncs creates a Visitor from functions, a nil function is a noop
+type VisitorFuncs struct {
+	EnterFunc func(path ValuePath, value cadence.Value) error
+	LeaveFunc func(path ValuePath, value cadence.Value) error
+}
+
+func (v VisitorFuncs) Enter(path ValuePath, value cadence.Value) error {
+	if v.EnterFunc == nil {
+		return nil
+	}
+	return v.EnterFunc(path, value)
+}
+
+func (v VisitorFuncs) Leave(path ValuePath, value cadence.Value) error {
+	if v.LeaveFunc == nil {
+		return nil
+	}
+	return v.LeaveFunc(path, value)
+}
+
+// / Walk a cadence value depth first calling the visitor when entering and leaving every value
+// /  It covers every kind of cadence value, nil values are not visited. An error from the visitor stops the walk and is returned
+func Walk(value cadence.Value, visitor Visitor) error {
+	return walk(nil, value, visitor)
+}
+
+func walk(path ValuePath, value cadence.Value, visitor Visitor) error {
+	if value == nil {
+		return nil
+	}
+
+	err := visitor.Enter(path, value)
+	if err != nil && !errors.Is(err, SkipValue) {
+		return err
+	}
+
+	if err == nil {
+		err = walkChildren(path, value, visitor)
+		if err != nil {
+			return err
+		}
+	}
+
+	return visitor.Leave(path, value)
+}
+
+func walkChildren(path ValuePath, value cadence.Value, visitor Visitor) error {
+	// limit the capacity so that appending always copies and the path given to the visitor is never changed afterwards
+	child := func(element PathElement) ValuePath {
+		return append(path[:len(path):len(path)], element)
+	}
+
+	switch value := value.(type) {
+	case cadence.Optional:
+		return walk(path, value.Value, visitor)
+	case cadence.Array:
+		for i, item := range value.Values {
+			if err := walk(child(PathElement{Kind: PathIndex, Index: i}), item, visitor); err != nil {
+				return err
+			}
+		}
+	case cadence.Dictionary:
+		for _, pair := range value.Pairs {
+			if err := walk(child(PathElement{Kind: PathKey, Key: pair.Key}), pair.Key, visitor); err != nil {
+				return err
+			}
+			if err := walk(child(PathElement{Kind: PathValue, Key: pair.Key}), pair.Value, visitor); err != nil {
+				return err
+			}
+		}
+	case cadence.PathCapability:
+		if err := walk(child(PathElement{Kind: PathField, Field: "address"}), value.Address, visitor); err != nil {
+			return err
+		}
+		return walk(child(PathElement{Kind: PathField, Field: "path"}), value.Path, visitor)
+	case cadence.IDCapability:
+		if err := walk(child(PathElement{Kind: PathField, Field: "address"}), value.Address, visitor); err != nil {
+			return err
+		}
+		return walk(child(PathElement{Kind: PathField, Field: "id"}), value.ID, visitor)
+	case cadence.PathLink:
+		return walk(child(PathElement{Kind: PathField, Field: "targetPath"}), value.TargetPath, visitor)
+	default:
+		values, fields, ok := compositeFields(value)
+		if !ok {
+			return nil
+		}
+		for i, item := range values {
+			if err := walk(child(PathElement{Kind: PathField, Field: compositeFieldName(fields, i)}), item, visitor); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// the field values and the type of a composite value, ok is false if the value is not a composite
+//
+//	the type is nil for values created without WithType
+func composite(value cadence.Value) ([]cadence.Value, cadence.CompositeType, bool) {
+	switch value := value.(type) {
+	case cadence.Struct:
+		if value.StructType == nil {
+			return value.Fields, nil, true
+		}
+		return value.Fields, value.StructType, true
+	case cadence.Resource:
+		if value.ResourceType == nil {
+			return value.Fields, nil, true
+		}
+		return value.Fields, value.ResourceType, true
+	case cadence.Event:
+		if value.EventType == nil {
+			return value.Fields, nil, true
+		}
+		return value.Fields, value.EventType, true
+	case cadence.Enum:
+		if value.EnumType == nil {
+			return value.Fields, nil, true
+		}
+		return value.Fields, value.EnumType, true
+	case cadence.Contract:
+		if value.ContractType == nil {
+			return value.Fields, nil, true
+		}
+		return value.Fields, value.ContractType, true
+	case cadence.Attachment:
+		if value.AttachmentType == nil {
+			return value.Fields, nil, true
+		}
+		return value.Fields, value.AttachmentType, true
+	}
+	return nil, nil, false
+}
+
+// the field values and field types of a composite value, ok is false if the value is not a composite
+//
+//	the field types are nil for values without type, compositeFieldName falls back to the index for them
+func compositeFields(value cadence.Value) ([]cadence.Value, []cadence.Field, bool) {
+	values, typ, ok := composite(value)
+	if !ok || typ == nil {
+		return values, nil, ok
+	}
+	return values, typ.CompositeFields(), true
+}
+
+// the type id of a composite value, ok is false if the value is not a composite or has no type
+func compositeTypeID(value cadence.Value) (string, bool) {
+	_, typ, ok := composite(value)
+	if !ok || typ == nil {
+		return "", false
+	}
+	return typ.ID(), true
+}
+
+// the name of field i in a composite, falls back to the index if the type does not describe the field
+func compositeFieldName(fields []cadence.Field, i int) string {
+	if i < len(fields) {
+		return fields[i].Identifier
+	}
+	return fmt.Sprint(i)
+}
@@ -0,0 +1,127 @@
+package underflow
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func walkTestValue() cadence.Value {
+	offerType := &cadence.StructType{
+		QualifiedIdentifier: "Market.Offer",
+		Fields: []cadence.Field{
+			{Identifier: "buyer", Type: cadence.AddressType{}},
+			{Identifier: "tags", Type: cadence.NewDictionaryType(cadence.StringType{}, cadence.UInt64Type{})},
+		},
+	}
+	offer := cadence.NewStruct([]cadence.Value{
+		cadence.NewAddress([8]byte{0, 0, 0, 0, 0, 0, 0, 1}),
+		cadence.NewDictionary([]cadence.KeyValuePair{{Key: cadenceString("foo"), Value: cadence.NewUInt64(1)}}),
+	}).WithType(offerType)
+
+	return cadence.NewOptional(cadence.NewArray([]cadence.Value{
+		offer,
+		cadence.NewPathCapability(cadence.NewAddress([8]byte{0, 0, 0, 0, 0, 0, 0, 2}), cadence.Path{Domain: common.PathDomainPublic, Identifier: "bar"}, cadence.StringType{}),
+	}))
+}
+
+func TestWalk(t *testing.T) {
+	events := []string{}
+	err := Walk(walkTestValue(), VisitorFuncs{
+		EnterFunc: func(path ValuePath, value cadence.Value) error {
+			events = append(events, fmt.Sprintf("enter %s %T", path, value))
+			return nil
+		},
+		LeaveFunc: func(path ValuePath, value cadence.Value) error {
+			events = append(events, fmt.Sprintf("leave %s", path))
+			return nil
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"enter  cadence.Optional",
+		"enter  cadence.Array",
+		"enter [0] cadence.Struct",
+		"enter [0].buyer cadence.Address",
+		"leave [0].buyer",
+		"enter [0].tags cadence.Dictionary",
+		`enter [0].tags{"foo"} cadence.String`,
+		`leave [0].tags{"foo"}`,
+		`enter [0].tags["foo"] cadence.UInt64`,
+		`leave [0].tags["foo"]`,
+		"leave [0].tags",
+		"leave [0]",
+		"enter [1] cadence.PathCapability",
+		"enter [1].address cadence.Address",
+		"leave [1].address",
+		"enter [1].path cadence.Path",
+		"leave [1].path",
+		"leave [1]",
+		"leave ",
+		"leave ",
+	}, events)
+}
+
+func TestWalkSkipValue(t *testing.T) {
+	paths := []string{}
+	err := Walk(walkTestValue(), VisitorFuncs{
+		EnterFunc: func(path ValuePath, value cadence.Value) error {
+			paths = append(paths, path.String())
+			if _, ok := value.(cadence.Struct); ok {
+				return SkipValue
+			}
+			return nil
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "", "[0]", "[1]", "[1].address", "[1].path"}, paths)
+}
+
+func TestWalkUntypedComposites(t *testing.T) {
+	value := cadence.NewArray([]cadence.Value{
+		cadence.NewStruct([]cadence.Value{cadence.String("foo")}),
+		cadence.NewEvent([]cadence.Value{cadence.NewUInt8(1), cadence.NewUInt8(2)}),
+	})
+	paths := []string{}
+	err := Walk(value, VisitorFuncs{
+		EnterFunc: func(path ValuePath, value cadence.Value) error {
+			typeID, _ := compositeTypeID(value)
+			paths = append(paths, path.String()+typeID)
+			return nil
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "[0]", "[0].0", "[1]", "[1].0", "[1].1"}, paths)
+
+	_, ok := compositeTypeID(cadence.NewStruct(nil))
+	assert.False(t, ok)
+}
+
+func TestWalkError(t *testing.T) {
+	stop := errors.New("stop")
+	visited := 0
+	err := Walk(walkTestValue(), VisitorFuncs{
+		EnterFunc: func(path ValuePath, value cadence.Value) error {
+			visited++
+			if _, ok := value.(cadence.Address); ok {
+				return stop
+			}
+			return nil
+		},
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 4, visited)
+}
+
+func TestValuePathLast(t *testing.T) {
+	_, ok := ValuePath{}.Last()
+	assert.False(t, ok)
+
+	last, ok := ValuePath{{Kind: PathField, Field: "offers"}, {Kind: PathIndex, Index: 2}}.Last()
+	assert.True(t, ok)
+	assert.Equal(t, "[2]", last.String())
+}